@@ -0,0 +1,171 @@
+package skiprope
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// readSize is the number of bytes read at a time by NewFromReader.
+const readSize = 512 * BucketSize
+
+// builder builds a rope from start to end in a single pass. Instead of drawing random heights, the heights of the knots are
+// chosen so that the skiplist is balanced: every 100/Bias-th knot is one level taller.
+type builder struct {
+	r     *Rope
+	last  [MaxHeight]skipknot // the last knot at each height, along with the counts before the knot
+	count int                 // number of knots built
+}
+
+// newBuilder creates a builder which appends to the end of the rope. The knots at the end of the rope have to belong to the rope.
+func newBuilder(r *Rope) *builder {
+	b := &builder{r: r}
+	s := skiplist{r: r}
+	s.at(r.runes)
+	for i := range b.last {
+		if i >= r.Head.height {
+			b.last[i].knot = &r.Head
+			continue
+		}
+		// the search path holds the counts between the start of each knot and the end of the rope
+		b.last[i] = skipknot{
+			knot:         s.s[i].knot,
+			skipped:      r.size - s.s[i].skipped,
+			skippedRunes: r.runes - s.s[i].skippedRunes,
+			skippedLines: r.lines - s.s[i].skippedLines,
+			skippedUTF16: r.codeUnits - s.s[i].skippedUTF16,
+		}
+	}
+	return b
+}
+
+// NewFromBytes creates a new Rope holding the given bytes. It is faster than inserting the bytes into an empty Rope.
+func NewFromBytes(data []byte) *Rope {
+	r := New()
+	b := newBuilder(r)
+	b.write(data)
+	b.finish()
+	return r
+}
+
+// NewFromReader creates a new Rope holding everything read from the reader.
+func NewFromReader(rd io.Reader) (*Rope, error) {
+	r := New()
+	b := newBuilder(r)
+	_, err := b.readFrom(rd)
+	b.finish()
+	return r, err
+}
+
+// ReadFrom implements io.ReaderFrom. It appends everything read from the reader to the end of the rope, in the same way as NewFromReader.
+func (r *Rope) ReadFrom(rd io.Reader) (n int64, err error) {
+	s := skiplist{r: r}
+	s.own(r.runes)
+	point := r.runes
+
+	b := newBuilder(r)
+	n, err = b.readFrom(rd)
+	b.finish()
+
+	if r.runes > point {
+		var data []byte
+		if r.history != nil || len(r.observers) > 0 {
+			data = r.SubstrBytes(point, r.runes)
+		}
+		r.afterInsert(point, r.runes-point, data)
+	}
+	return n, err
+}
+
+// readFrom appends everything read from the reader. It returns the number of bytes read.
+func (b *builder) readFrom(rd io.Reader) (retVal int64, err error) {
+	buf := make([]byte, readSize+utf8.UTFMax)
+	var carry int // number of bytes of an incomplete UTF-8 sequence left over from the previous read
+	for {
+		var n int
+		n, err = rd.Read(buf[carry:readSize])
+		retVal += int64(n)
+		n += carry
+		full := fullRunes(buf[:n])
+		if err == io.EOF {
+			full = n
+		}
+		b.write(buf[:full])
+		carry = copy(buf, buf[full:n])
+
+		if err == io.EOF {
+			return retVal, nil
+		}
+		if err != nil {
+			// the incomplete sequence is kept, as it is not going to be completed
+			b.write(buf[:carry])
+			return retVal, err
+		}
+	}
+}
+
+// height returns the height of the next knot.
+func (b *builder) height() int {
+	period := 2
+	if bias := b.r.heightBias(); bias > 0 && bias < 50 {
+		period = 100 / bias
+	}
+	retVal := 1
+	for n := b.count; n%period == 0 && retVal < MaxHeight-1; n /= period {
+		retVal++
+	}
+	return retVal
+}
+
+// write appends the data to the end of the rope.
+func (b *builder) write(data []byte) {
+	for len(data) > 0 {
+		n, runes := chunk(data, b.r.bucketSize)
+		b.add(data[:n], runes)
+		data = data[n:]
+	}
+}
+
+// add appends a new knot to the end of the rope.
+func (b *builder) add(data []byte, runes int) {
+	r := b.r
+	b.count++
+	height := b.height()
+	k := newKnot(height, r.epoch, r.bucketSize)
+	k.used = copy(k.data, data)
+
+	for r.Head.height <= height {
+		r.Head.height++
+	}
+
+	start := skipknot{k, r.size, r.runes, r.lines, r.codeUnits}
+	for i := 0; i < height; i++ {
+		prev := b.last[i]
+		prev.knot.nexts[i] = skipknot{
+			knot:         k,
+			skipped:      start.skipped - prev.skipped,
+			skippedRunes: start.skippedRunes - prev.skippedRunes,
+			skippedLines: start.skippedLines - prev.skippedLines,
+			skippedUTF16: start.skippedUTF16 - prev.skippedUTF16,
+		}
+		b.last[i] = start
+	}
+
+	r.size += len(data)
+	r.runes += runes
+	r.lines += countLines(data)
+	r.codeUnits += countUTF16(data)
+}
+
+// finish links the last knot at each height to the end of the rope.
+func (b *builder) finish() {
+	r := b.r
+	for i := 0; i < r.Head.height; i++ {
+		prev := b.last[i]
+		prev.knot.nexts[i] = skipknot{
+			skipped:      r.size - prev.skipped,
+			skippedRunes: r.runes - prev.skippedRunes,
+			skippedLines: r.lines - prev.skippedLines,
+			skippedUTF16: r.codeUnits - prev.skippedUTF16,
+		}
+	}
+}
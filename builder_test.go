@@ -0,0 +1,150 @@
+package skiprope
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFromBytes(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewFromBytes(nil)
+	validRope(t, r)
+	assert.Equal(0, r.Size())
+	assert.Equal("", r.String())
+
+	data := strings.Repeat(a+"\n"+"𝄞 surrogate pairs\n", 50)
+	r = NewFromBytes([]byte(data))
+	validRope(t, r)
+
+	expected := New()
+	if err := expected.Insert(0, data); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(data, r.String())
+	assert.Equal(expected.Size(), r.Size())
+	assert.Equal(expected.Runes(), r.Runes())
+	assert.Equal(expected.LineCount(), r.LineCount())
+	assert.Equal(expected.UTF16Len(), r.UTF16Len())
+	for i := 0; i <= r.Runes(); i += 37 {
+		assert.Equal(expected.Index(i), r.Index(i), "Index(%d)", i)
+		assert.Equal(expected.ByteOffset(i), r.ByteOffset(i), "ByteOffset(%d)", i)
+		assert.Equal(expected.UTF16Offset(i), r.UTF16Offset(i), "UTF16Offset(%d)", i)
+	}
+	for i := 0; i < r.LineCount(); i++ {
+		assert.Equal(expected.LineStart(i), r.LineStart(i), "LineStart(%d)", i)
+	}
+
+	// the knots are balanced
+	assert.True(r.Head.height > 2, "expected a taller head. Got %d", r.Head.height)
+
+	// the rope can be edited like any other
+	rnd := rand.New(rand.NewSource(1337))
+	model := []rune(data)
+	for i := 0; i < 200; i++ {
+		point := rnd.Intn(len(model) + 1)
+		if rnd.Intn(2) == 0 {
+			if err := r.Insert(point, "ß\n"); err != nil {
+				t.Fatal(err)
+			}
+			model = append(model[:point], append([]rune("ß\n"), model[point:]...)...)
+			continue
+		}
+		n := min(rnd.Intn(100), len(model)-point)
+		if err := r.EraseAt(point, n); err != nil {
+			t.Fatal(err)
+		}
+		model = append(model[:point], model[point+n:]...)
+	}
+	validRope(t, r)
+	assert.Equal(string(model), r.String())
+	assert.Equal(strings.Count(string(model), "\n")+1, r.LineCount())
+}
+
+func TestNewFromBytes_InvalidUTF8(t *testing.T) {
+	data := []byte(strings.Repeat("世\xff\xe4", 100))
+	r := NewFromBytes(data)
+	validRope(t, r)
+	assert.Equal(t, data, []byte(r.String()))
+}
+
+func TestNewFromReader(t *testing.T) {
+	assert := assert.New(t)
+	data := strings.Repeat("Hello 世界! 𝄞\n", 5000)
+
+	r, err := NewFromReader(strings.NewReader(data))
+	assert.Nil(err)
+	validRope(t, r)
+	assert.Equal(data, r.String())
+
+	// multibyte runes split across reads are put back together
+	r, err = NewFromReader(iotest.OneByteReader(strings.NewReader(data[:1000])))
+	assert.Nil(err)
+	validRope(t, r)
+	assert.Equal(data[:1000], r.String())
+	assert.Equal(NewFromBytes([]byte(data[:1000])).Runes(), r.Runes())
+
+	// an incomplete rune at the end is kept
+	r, err = NewFromReader(bytes.NewReader([]byte("abc\xe4\xb8")))
+	assert.Nil(err)
+	assert.Equal("abc\xe4\xb8", r.String())
+
+	errRead := errors.New("read error")
+	r, err = NewFromReader(iotest.TimeoutReader(iotest.HalfReader(strings.NewReader(data))))
+	assert.Equal(iotest.ErrTimeout, err)
+	validRope(t, r)
+
+	_, err = NewFromReader(iotest.ErrReader(errRead))
+	assert.Equal(errRead, err)
+}
+
+func TestRope_ReadFrom(t *testing.T) {
+	assert := assert.New(t)
+	data := strings.Repeat("Hello 世界! 𝄞\n", 500)
+
+	r := New()
+	r.Insert(0, "Existing text\n")
+	h := NewHistory(r)
+	m := r.NewMark(r.Runes(), RightGravity)
+	var changes []Change
+	r.Subscribe(func(c Change) { changes = append(changes, c) })
+
+	n, err := r.ReadFrom(iotest.HalfReader(strings.NewReader(data)))
+	assert.Nil(err)
+	assert.Equal(int64(len(data)), n)
+	validRope(t, r)
+	assert.Equal("Existing text\n"+data, r.String())
+	assert.Equal(r.Runes(), m.Pos())
+	if assert.Len(changes, 1) {
+		assert.Equal(data, string(changes[0].Inserted))
+	}
+
+	// the rope can still be edited
+	if err := r.Insert(20, "edit"); err != nil {
+		t.Fatal(err)
+	}
+	validRope(t, r)
+
+	if err := h.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("Existing text\n", r.String())
+
+	// appending to a rope shared with a snapshot
+	snap := r.Snapshot()
+	if _, err := r.ReadFrom(strings.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	validRope(t, r)
+	assert.Equal("Existing text\n"+data, r.String())
+	assert.Equal("Existing text\n", snap.String())
+}
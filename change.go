@@ -0,0 +1,49 @@
+package skiprope
+
+// Position is a line and column in a rope. Both are 0-indexed, and the column is counted in runes.
+type Position struct {
+	Line, Col int
+}
+
+// Change describes an edit made to a rope. An edit inserts text, erases text, or replaces text, in which case it does both.
+type Change struct {
+	Point  int // the point of the edit
+	Offset int // the byte offset of the edit
+
+	Inserted    []byte // the inserted text. It must not be modified.
+	Erased      int    // number of runes erased
+	ErasedBytes int    // number of bytes erased
+
+	Start  Position // the position of the edit
+	OldEnd Position // the position of the end of the erased text, before the edit
+	NewEnd Position // the position of the end of the inserted text, after the edit
+}
+
+type observer struct {
+	id int
+	fn func(Change)
+}
+
+// Subscribe adds a function that is called after every edit made to the rope. The function is called synchronously,
+// after the rope has been updated, so it may read from the rope. It must not modify the rope.
+//
+// The returned function removes the subscription.
+func (r *Rope) Subscribe(fn func(Change)) (unsubscribe func()) {
+	r.observed++
+	id := r.observed
+	r.observers = append(r.observers, observer{id: id, fn: fn})
+	return func() {
+		for i, o := range r.observers {
+			if o.id == id {
+				r.observers = append(r.observers[:i:i], r.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (r *Rope) notify(c Change) {
+	for _, o := range r.observers {
+		o.fn(c)
+	}
+}
@@ -0,0 +1,37 @@
+package skiprope
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRope_Subscribe(t *testing.T) {
+	r := New()
+	r.Insert(0, "Hello\nWorld")
+
+	var changes []Change
+	unsubscribe := r.Subscribe(func(c Change) {
+		// the rope is already updated when the observer is called
+		assert.Equal(t, c.Point+len([]rune(string(c.Inserted))), r.LineColToPoint(c.NewEnd.Line, c.NewEnd.Col))
+		changes = append(changes, c)
+	})
+
+	r.Insert(6, "你好\n")
+	r.EraseAt(2, 7)
+	r.InsertAtByte(0, []byte("!"))
+	r.EraseBytes(0, 1)
+	assert.Equal(t, "HeWorld", r.String())
+
+	expected := []Change{
+		{Point: 6, Offset: 6, Inserted: []byte("你好\n"), Start: Position{1, 0}, OldEnd: Position{1, 0}, NewEnd: Position{2, 0}},
+		{Point: 2, Offset: 2, Erased: 7, ErasedBytes: 11, Start: Position{0, 2}, OldEnd: Position{2, 0}, NewEnd: Position{0, 2}},
+		{Point: 0, Offset: 0, Inserted: []byte("!"), Start: Position{0, 0}, OldEnd: Position{0, 0}, NewEnd: Position{0, 1}},
+		{Point: 0, Offset: 0, Erased: 1, ErasedBytes: 1, Start: Position{0, 0}, OldEnd: Position{0, 1}, NewEnd: Position{0, 0}},
+	}
+	assert.Equal(t, expected, changes)
+
+	unsubscribe()
+	r.Insert(0, "unobserved")
+	assert.Equal(t, 4, len(changes))
+}
@@ -0,0 +1,461 @@
+package skiprope
+
+import (
+	"io"
+	"regexp"
+	"sync"
+)
+
+// ConcurrentRope is a Rope which is safe for concurrent use. Reads are guarded by a read lock, so that they may run in parallel,
+// while edits are guarded by a write lock. It has its own source of randomness, so it never contends with other ropes.
+//
+// The methods which hand out something that keeps referring to the rope (marks, scanners, histories, iterators and the like)
+// are not wrapped. Use them within View or Update instead, and do not let them escape the function.
+type ConcurrentRope struct {
+	mu sync.RWMutex
+	r  *Rope
+}
+
+// NewConcurrent creates a new ConcurrentRope.
+func NewConcurrent() *ConcurrentRope { return NewConcurrentWithOptions(Options{}) }
+
+// NewConcurrentWithOptions creates a new ConcurrentRope with the given options. If the options do not give the rope
+// a source of randomness of its own, it is given one.
+func NewConcurrentWithOptions(opts Options) *ConcurrentRope {
+	r := NewWithOptions(opts)
+	if r.rand == nil {
+		r.rand = newRand()
+	}
+	return &ConcurrentRope{r: r}
+}
+
+// View calls the function with the rope under a read lock, so that several reads see the same contents.
+// The function must not modify the rope.
+func (c *ConcurrentRope) View(fn func(r *Rope)) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	fn(c.r)
+}
+
+// Update calls the function with the rope under a write lock, so that several edits are applied together.
+func (c *ConcurrentRope) Update(fn func(r *Rope)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(c.r)
+}
+
+// Snapshot returns a snapshot of the rope. The snapshot is a plain *Rope, which may be read without any locking.
+// Taking a snapshot modifies the rope, so it takes the write lock.
+func (c *ConcurrentRope) Snapshot() *Rope {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.Snapshot()
+}
+
+// Subscribe adds a function that is called after every edit made to the rope. The function is called with the write lock held,
+// so it must not call the methods of the ConcurrentRope. The returned function removes the subscription.
+func (c *ConcurrentRope) Subscribe(fn func(Change)) (unsubscribe func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	remove := c.r.Subscribe(fn)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		remove()
+	}
+}
+
+/* READS */
+
+// Validate checks the invariants of the skiplist of the rope. See Rope.Validate.
+func (c *ConcurrentRope) Validate() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.Validate()
+}
+
+// Dump returns a description of the skiplist of the rope, for debugging. See Rope.Dump.
+func (c *ConcurrentRope) Dump() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.Dump()
+}
+
+// Size is the length of the rope.
+func (c *ConcurrentRope) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.Size()
+}
+
+// Runes is the number of runes in the rope.
+func (c *ConcurrentRope) Runes() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.Runes()
+}
+
+// LineCount returns the number of lines in the rope.
+func (c *ConcurrentRope) LineCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.LineCount()
+}
+
+// UTF16Len returns the number of UTF-16 code units in the rope.
+func (c *ConcurrentRope) UTF16Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.UTF16Len()
+}
+
+// String returns the contents of the rope.
+func (c *ConcurrentRope) String() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.String()
+}
+
+// Substr returns the runes between the two points as a string.
+func (c *ConcurrentRope) Substr(pointA, pointB int) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.Substr(pointA, pointB)
+}
+
+// SubstrE returns the runes between the two points as a string, or ErrOutOfRange if either point is out of bounds.
+func (c *ConcurrentRope) SubstrE(pointA, pointB int) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.SubstrE(pointA, pointB)
+}
+
+// SubstrBytes returns the runes between the two points as a slice of bytes.
+func (c *ConcurrentRope) SubstrBytes(pointA, pointB int) []byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.SubstrBytes(pointA, pointB)
+}
+
+// SubstrRunes returns the runes between the two points.
+func (c *ConcurrentRope) SubstrRunes(pointA, pointB int) []rune {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.SubstrRunes(pointA, pointB)
+}
+
+// SubstrByteRange returns the bytes between the two byte offsets.
+func (c *ConcurrentRope) SubstrByteRange(byteA, byteB int) []byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.SubstrByteRange(byteA, byteB)
+}
+
+// Index returns the rune at the given point.
+func (c *ConcurrentRope) Index(at int) rune {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.Index(at)
+}
+
+// IndexE returns the rune at the given point, or ErrOutOfRange if there is no rune at the point.
+func (c *ConcurrentRope) IndexE(at int) (rune, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.IndexE(at)
+}
+
+// ByteOffset returns the byte offset of the rune at the given point.
+func (c *ConcurrentRope) ByteOffset(at int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.ByteOffset(at)
+}
+
+// RuneOffset returns the point of the rune at the given byte offset.
+func (c *ConcurrentRope) RuneOffset(at int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.RuneOffset(at)
+}
+
+// UTF16Offset returns the UTF-16 offset of the given point.
+func (c *ConcurrentRope) UTF16Offset(point int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.UTF16Offset(point)
+}
+
+// PointFromUTF16 returns the point of the given UTF-16 offset.
+func (c *ConcurrentRope) PointFromUTF16(off int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.PointFromUTF16(off)
+}
+
+// LineStart returns the point of the first rune of the given line.
+func (c *ConcurrentRope) LineStart(line int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.LineStart(line)
+}
+
+// PointToLineCol returns the line and column of the rune at the given point.
+func (c *ConcurrentRope) PointToLineCol(at int) (line, col int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.PointToLineCol(at)
+}
+
+// LineColToPoint returns the point of the given line and column.
+func (c *ConcurrentRope) LineColToPoint(line, col int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.LineColToPoint(line, col)
+}
+
+// IndexOf returns the point of the first occurrence of the needle at or after the given point, or -1.
+func (c *ConcurrentRope) IndexOf(needle string, from int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.IndexOf(needle, from)
+}
+
+// LastIndexOf returns the point of the last occurrence of the needle which ends at or before the given point, or -1.
+func (c *ConcurrentRope) LastIndexOf(needle string, from int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.LastIndexOf(needle, from)
+}
+
+// Before returns the point after the nearest rune before the given point which matches the function.
+// The function is called under the read lock.
+func (c *ConcurrentRope) Before(at int, fn func(r rune) bool) (int, rune, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.Before(at, fn)
+}
+
+// After returns the point of the nearest rune at or after the given point which matches the function.
+// The function is called under the read lock.
+func (c *ConcurrentRope) After(at int, fn func(r rune) bool) (int, rune, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.After(at, fn)
+}
+
+// FindFunc returns the point of the first rune at or after the given point which matches the function.
+// The function is called under the read lock.
+func (c *ConcurrentRope) FindFunc(from int, fn func(r rune) bool) (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.FindFunc(from, fn)
+}
+
+// FindRegexp returns the locations of the leftmost match of the regular expression at or after the given point.
+func (c *ConcurrentRope) FindRegexp(re *regexp.Regexp, from int) []int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.FindRegexp(re, from)
+}
+
+// FindAllRegexp returns the locations of up to n matches of the regular expression at or after the given point.
+func (c *ConcurrentRope) FindAllRegexp(re *regexp.Regexp, from, n int) [][]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.FindAllRegexp(re, from, n)
+}
+
+// NextGrapheme returns the point of the first grapheme cluster boundary after the given point.
+func (c *ConcurrentRope) NextGrapheme(point int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.NextGrapheme(point)
+}
+
+// PrevGrapheme returns the point of the last grapheme cluster boundary before the given point.
+func (c *ConcurrentRope) PrevGrapheme(point int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.PrevGrapheme(point)
+}
+
+// GraphemeCount returns the number of grapheme clusters which overlap the runes between the two points.
+func (c *ConcurrentRope) GraphemeCount(pointA, pointB int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.GraphemeCount(pointA, pointB)
+}
+
+// WordAt returns the bounds of the word segment which the rune at the given point is in.
+func (c *ConcurrentRope) WordAt(point int) (start, end int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.WordAt(point)
+}
+
+// NextWordBoundary returns the point of the first word boundary after the given point.
+func (c *ConcurrentRope) NextWordBoundary(point int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.NextWordBoundary(point)
+}
+
+// PrevWordBoundary returns the point of the last word boundary before the given point.
+func (c *ConcurrentRope) PrevWordBoundary(point int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.PrevWordBoundary(point)
+}
+
+// SentenceAt returns the bounds of the sentence which the rune at the given point is in.
+func (c *ConcurrentRope) SentenceAt(point int) (start, end int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.SentenceAt(point)
+}
+
+// NextSentenceBoundary returns the point of the first sentence boundary after the given point.
+func (c *ConcurrentRope) NextSentenceBoundary(point int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.NextSentenceBoundary(point)
+}
+
+// PrevSentenceBoundary returns the point of the last sentence boundary before the given point.
+func (c *ConcurrentRope) PrevSentenceBoundary(point int) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.PrevSentenceBoundary(point)
+}
+
+// ReadAt implements io.ReaderAt.
+func (c *ConcurrentRope) ReadAt(p []byte, off int64) (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.ReadAt(p, off)
+}
+
+// WriteTo implements io.WriterTo. The read lock is held until all the bytes are written.
+func (c *ConcurrentRope) WriteTo(w io.Writer) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.r.WriteTo(w)
+}
+
+/* EDITS */
+
+// Insert inserts the string at the given point.
+func (c *ConcurrentRope) Insert(at int, str string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.Insert(at, str)
+}
+
+// InsertBytes inserts the bytes at the given point.
+func (c *ConcurrentRope) InsertBytes(point int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.InsertBytes(point, data)
+}
+
+// InsertRunes inserts the runes at the given point.
+func (c *ConcurrentRope) InsertRunes(point int, data []rune) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.InsertRunes(point, data)
+}
+
+// InsertAtByte inserts the bytes at the given byte offset.
+func (c *ConcurrentRope) InsertAtByte(at int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.InsertAtByte(at, data)
+}
+
+// InsertUTF16 inserts the string at the given UTF-16 offset.
+func (c *ConcurrentRope) InsertUTF16(off int, str string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.InsertUTF16(off, str)
+}
+
+// EraseAt erases n runes starting from the given point.
+func (c *ConcurrentRope) EraseAt(point, n int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.EraseAt(point, n)
+}
+
+// EraseBytes erases n bytes starting from the given byte offset.
+func (c *ConcurrentRope) EraseBytes(at, n int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.EraseBytes(at, n)
+}
+
+// EraseAtUTF16 erases n UTF-16 code units starting from the given UTF-16 offset.
+func (c *ConcurrentRope) EraseAtUTF16(off, n int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.EraseAtUTF16(off, n)
+}
+
+// Replace replaces n runes starting from the given point with the bytes.
+func (c *ConcurrentRope) Replace(point, n int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.Replace(point, n, data)
+}
+
+// ApplyEdits applies a batch of non-overlapping edits.
+func (c *ConcurrentRope) ApplyEdits(edits []Edit) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.ApplyEdits(edits)
+}
+
+// ReplaceAllRegexp replaces all the matches of the regular expression with the template.
+func (c *ConcurrentRope) ReplaceAllRegexp(re *regexp.Regexp, template string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.ReplaceAllRegexp(re, template)
+}
+
+// Write implements io.Writer. The bytes are appended to the end of the rope.
+func (c *ConcurrentRope) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.Write(p)
+}
+
+// ReadFrom implements io.ReaderFrom. The write lock is held until the reader is exhausted.
+func (c *ConcurrentRope) ReadFrom(rd io.Reader) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.r.ReadFrom(rd)
+}
+
+// Split moves the runes after the point to a new rope, which is returned. Unlike the rope, the new rope is not safe for concurrent use.
+func (c *ConcurrentRope) Split(point int) *Rope {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, retVal := c.r.Split(point)
+	return retVal
+}
+
+// Concat moves all the runes of the other rope to the end of the rope, leaving the other rope empty.
+// The other rope must not be used by another goroutine meanwhile.
+func (c *ConcurrentRope) Concat(other *Rope) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.r.Concat(other)
+}
+
+// Splice moves all the runes of the other rope into the rope at the given point, leaving the other rope empty.
+// The other rope must not be used by another goroutine meanwhile.
+func (c *ConcurrentRope) Splice(point int, other *Rope) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.r.Splice(point, other)
+}
@@ -0,0 +1,86 @@
+package skiprope
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentRope(t *testing.T) {
+	c := NewConcurrent()
+	const writers, edits = 4, 200
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			line := fmt.Sprintf("writer %d 你好\n", i)
+			for j := 0; j < edits; j++ {
+				if err := c.Insert(c.Runes(), line); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < edits; j++ {
+				// the contents only ever grow by whole lines
+				c.View(func(r *Rope) {
+					s := r.String()
+					assert.Equal(t, r.LineCount()-1, strings.Count(s, "\n"))
+					assert.True(t, strings.HasSuffix(s, "\n") || s == "")
+				})
+			}
+		}()
+	}
+
+	// plain ropes share a source of randomness, which has to be safe for concurrent use too
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := New()
+			for j := 0; j < edits; j++ {
+				r.Insert(0, "Hello World\n")
+			}
+			validRope(t, r)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, writers*edits+1, c.LineCount())
+	c.View(func(r *Rope) { validRope(t, r) })
+
+	// several edits under one lock
+	c.Update(func(r *Rope) {
+		r.EraseAt(0, r.Runes())
+		r.Insert(0, "Hello World")
+	})
+	snap := c.Snapshot()
+	c.Replace(6, 5, []byte("there"))
+	assert.Equal(t, "Hello World", snap.String())
+	assert.Equal(t, "Hello there", c.String())
+
+	// moving runes between ropes
+	var changes int
+	unsubscribe := c.Subscribe(func(Change) { changes++ })
+	tail := c.Split(5)
+	assert.Equal(t, " there", tail.String())
+	c.Concat(tail)
+	head := New()
+	head.Insert(0, ">> ")
+	c.Splice(0, head)
+	unsubscribe()
+	c.Insert(0, "!")
+	assert.Equal(t, "!>> Hello there", c.String())
+	assert.Equal(t, 3, changes)
+	assert.Nil(t, c.Validate())
+	assert.Contains(t, c.Dump(), "size 15, runes 15, lines 0")
+}
@@ -0,0 +1,32 @@
+package skiprope
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dump returns a description of the skiplist of the rope, one knot per line, for debugging. Each line has the point and
+// byte offset of the knot, its tower (one '|' per level), the number of bytes it uses out of its capacity, the number of
+// runes skipped by each of its links, and its contents.
+//
+// Together with a seeded rope (see NewWithOptions), this makes it possible to reproduce the exact shape of a failing rope.
+func (r *Rope) Dump() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "size %d, runes %d, lines %d, height %d\n", r.size, r.runes, r.lines, r.Head.height)
+	point, offset := 0, 0
+	for k := &r.Head; k != nil; k = k.nexts[0].knot {
+		skips := make([]string, k.height)
+		for i := range skips {
+			skips[i] = fmt.Sprint(k.nexts[i].skippedRunes)
+		}
+		fmt.Fprintf(&buf, "%6d %6d %-*s %d/%d [%s] %q\n",
+			point, offset,
+			r.Head.height, strings.Repeat("|", k.height),
+			k.used, len(k.data),
+			strings.Join(skips, " "),
+			k.data[:k.used])
+		point += k.nexts[0].skippedRunes
+		offset += k.nexts[0].skipped
+	}
+	return buf.String()
+}
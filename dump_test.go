@@ -0,0 +1,43 @@
+package skiprope
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRope_Dump(t *testing.T) {
+	assert := assert.New(t)
+
+	build := func(r *Rope) *Rope {
+		for i := 0; i < 50; i++ {
+			if err := r.Insert(i*3%(r.Runes()+1), "héllo\n"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := r.EraseAt(10, 40); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	a := build(NewWithOptions(Options{Seed: 1337, BucketSize: 16}))
+	b := build(NewWithOptions(Options{Seed: 1337, BucketSize: 16}))
+	assert.Equal(a.Dump(), b.Dump())
+	assert.True(strings.HasPrefix(a.Dump(), "size 296, runes 260, lines 50, height "), a.Dump())
+
+	// every knot, including the head, is on a line of its own
+	knots := 0
+	for k := &a.Head; k != nil; k = k.nexts[0].knot {
+		knots++
+	}
+	assert.Equal(knots+1, strings.Count(a.Dump(), "\n"))
+
+	// ropes without a source of their own are reproducible once the shared source is seeded
+	Seed(1337)
+	c := build(NewWithOptions(Options{BucketSize: 16}))
+	Seed(1337)
+	d := build(NewWithOptions(Options{BucketSize: 16}))
+	assert.Equal(c.Dump(), d.Dump())
+}
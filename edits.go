@@ -0,0 +1,98 @@
+package skiprope
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Edit is a replacement of N runes starting from Point with Data. An insertion has an N of 0, while an erasure has no Data.
+type Edit struct {
+	Point int
+	N     int
+	Data  []byte
+}
+
+// OverlapError is returned by ApplyEdits when two edits overlap. A and B are the indices of the edits.
+type OverlapError struct {
+	A, B int
+}
+
+func (err OverlapError) Error() string { return fmt.Sprintf("Edits %d and %d overlap", err.A, err.B) }
+
+// ApplyEdits applies a batch of edits to the rope. The points of the edits refer to the rope before any of the edits are applied,
+// as is the case with multiple cursors or LSP text edits. The edits are applied in a single left to right walk over the rope.
+//
+// Insertions at the same point are applied in the order they are given, and before a replacement at that point.
+// If any of the edits are out of bounds, or if they overlap, none of the edits are applied and an error is returned.
+//
+// The batch is undone and redone in one step. The observers are notified of every edit once all of them are applied,
+// each Change describing the rope as it was after the edits before it.
+func (r *Rope) ApplyEdits(edits []Edit) (err error) {
+	order := make([]int, len(edits))
+	for i, e := range edits {
+		if e.Point < 0 || e.N < 0 || e.Point > r.runes-e.N {
+			return ErrOutOfRange
+		}
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := edits[order[i]], edits[order[j]]
+		return a.Point < b.Point || (a.Point == b.Point && a.N == 0 && b.N > 0)
+	})
+	for i := 1; i < len(order); i++ {
+		if a, b := edits[order[i-1]], edits[order[i]]; a.Point+a.N > b.Point {
+			return OverlapError{order[i-1], order[i]}
+		}
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+
+	if r.history != nil {
+		r.history.Begin()
+		defer r.history.Commit()
+	}
+
+	s := skiplist{r: r}
+	last := edits[order[len(order)-1]]
+	s.own(last.Point + last.N)
+
+	var changes []Change
+	var delta int // number of runes added by the edits so far
+	for i, j := range order {
+		e := edits[j]
+		point := e.Point + delta
+
+		var k *knot
+		if i == 0 {
+			k, _, _, err = s.find(point)
+		} else {
+			k, _, err = s.findFrom(point)
+		}
+		if err != nil {
+			return err
+		}
+
+		erased, c := r.beforeReplace(point, e.N)
+		runes := r.runes
+		s.del(k, e.N)
+		// the insertion may move the search path past the point, but the path from before the insertion is still valid.
+		path := s.s
+		if err = s.insert(k, e.Data); err != nil {
+			return err
+		}
+		s.s = path
+
+		runes = r.runes - runes + e.N
+		r.afterReplace(point, runes, erased, e.Data, c)
+		if c != nil {
+			changes = append(changes, *c)
+		}
+		delta += runes - e.N
+	}
+
+	for _, c := range changes {
+		r.notify(c)
+	}
+	return nil
+}
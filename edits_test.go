@@ -0,0 +1,118 @@
+package skiprope
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRope_ApplyEdits(t *testing.T) {
+	const alphabet = "ab\n你好 😀"
+	ar := []rune(alphabet)
+	rnd := rand.New(rand.NewSource(1337))
+
+	r := New()
+	model := []rune(strings.Repeat("Hello World! 你好世界!\n", 20))
+	if err := r.InsertRunes(0, model); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 200; i++ {
+		// pick non-overlapping edits
+		var edits []Edit
+		for point := rnd.Intn(20); point <= len(model); point += 1 + rnd.Intn(40) {
+			n := min(rnd.Intn(10), len(model)-point)
+			if rnd.Intn(3) == 0 {
+				n = 0
+			}
+			ins := make([]rune, rnd.Intn(12))
+			for j := range ins {
+				ins[j] = ar[rnd.Intn(len(ar))]
+			}
+			edits = append(edits, Edit{Point: point, N: n, Data: []byte(string(ins))})
+			point += n
+		}
+		rnd.Shuffle(len(edits), func(i, j int) { edits[i], edits[j] = edits[j], edits[i] })
+
+		snap := r.Snapshot()
+		if err := r.ApplyEdits(edits); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, string(model), snap.String())
+
+		sort.Slice(edits, func(i, j int) bool { return edits[i].Point > edits[j].Point })
+		for _, e := range edits {
+			model = append(model[:e.Point:e.Point], append([]rune(string(e.Data)), model[e.Point+e.N:]...)...)
+		}
+		if !assert.Equal(t, string(model), r.String()) {
+			t.FailNow()
+		}
+
+		// keep the rope from growing too much
+		if len(model) > 2000 {
+			if err := r.EraseAt(0, 1000); err != nil {
+				t.Fatal(err)
+			}
+			model = model[1000:]
+		}
+	}
+	validRope(t, r)
+}
+
+func TestRope_ApplyEdits_Errors(t *testing.T) {
+	assert := assert.New(t)
+	r := New()
+	r.Insert(0, "Hello World")
+
+	err := r.ApplyEdits([]Edit{{Point: 6, N: 5, Data: []byte("there")}, {Point: 0, N: 1}, {Point: 4, N: 3}})
+	assert.Equal(OverlapError{2, 0}, err)
+	err = r.ApplyEdits([]Edit{{Point: 6, N: 1}, {Point: 6, N: 1}})
+	assert.Equal(OverlapError{0, 1}, err)
+	err = r.ApplyEdits([]Edit{{Point: 6, N: 1}, {Point: 12}})
+	assert.NotNil(err)
+	err = r.ApplyEdits([]Edit{{Point: 6, N: -1}})
+	assert.NotNil(err)
+
+	// none of the edits are applied
+	assert.Equal("Hello World", r.String())
+
+	// insertions at the same point are applied in order, before a replacement at that point
+	err = r.ApplyEdits([]Edit{{Point: 6, N: 5, Data: []byte("World")}, {Point: 6, Data: []byte("Big ")}, {Point: 6, Data: []byte("Wide ")}})
+	assert.Nil(err)
+	assert.Equal("Hello Big Wide World", r.String())
+}
+
+func TestRope_ApplyEdits_Hooks(t *testing.T) {
+	assert := assert.New(t)
+	r := New()
+	r.Insert(0, "foo bar foo baz foo")
+	h := NewHistory(r)
+	m := r.NewMark(9, RightGravity)
+
+	var changes []Change
+	r.Subscribe(func(c Change) { changes = append(changes, c) })
+
+	err := r.ApplyEdits([]Edit{
+		{Point: 16, N: 3, Data: []byte("quux")},
+		{Point: 0, N: 3, Data: []byte("quux")},
+		{Point: 8, N: 3, Data: []byte("quux")},
+	})
+	assert.Nil(err)
+	assert.Equal("quux bar quux baz quux", r.String())
+	assert.Equal(13, m.Pos())
+
+	if assert.Len(changes, 3) {
+		assert.Equal(0, changes[0].Point)
+		assert.Equal(9, changes[1].Point)
+		assert.Equal(18, changes[2].Point)
+	}
+
+	if err := h.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("foo bar foo baz foo", r.String())
+	assert.False(h.CanUndo())
+}
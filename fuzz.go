@@ -7,20 +7,40 @@ import (
 	"unicode/utf8"
 )
 
+// fuzzOptions seeds every rope, so that a failing input gives the same skiplist on every run.
+var fuzzOptions = Options{Seed: 1}
+
+// validate crashes the fuzzer if the rope is corrupt. Ropes with ill-formed UTF-8 are not checked (see Validate).
+func validate(r *Rope) {
+	if !utf8.ValidString(r.String()) {
+		return
+	}
+	if err := r.Validate(); err != nil {
+		println(err.Error())
+		println(r.Dump())
+		os.Exit(1)
+	}
+}
+
 func Fuzz(data []byte) int {
-	r := New()
+	r := NewWithOptions(fuzzOptions)
 	if err := r.Insert(0, string(data)); err != nil {
 		return 0
 	}
-	rb := New()
+	rb := NewWithOptions(fuzzOptions)
 	if err := rb.InsertBytes(0, data); err != nil {
 		return 0
 	}
 
+	validate(r)
+	validate(rb)
+
 	if r.String() != rb.String() {
 		println("Insert and InsertBytes did not insert equally")
 		println(r.String())
 		println(rb.String())
+		println(r.Dump())
+		println(rb.Dump())
 		os.Exit(1)
 	}
 
@@ -31,15 +51,17 @@ func Fuzz(data []byte) int {
 		w = width
 	}
 
-	rr := New()
+	rr := NewWithOptions(fuzzOptions)
 	if err := rr.InsertRunes(0, runeData); err != nil {
 		return 0
 	}
+	validate(rr)
 
 	// Insert not at = 0
 	if err := rb.InsertBytes(len(data)/3, data); err != nil {
 		return 0
 	}
+	validate(rb)
 	_ = rb.String()
 
 	// Before
@@ -57,51 +79,52 @@ func Fuzz(data []byte) int {
 	}
 
 	// EraseAt
-	eraser := New()
+	eraser := NewWithOptions(fuzzOptions)
 	if err := eraser.InsertBytes(0, data); err != nil {
 		return 0
 	}
 	if err := eraser.EraseAt(len(data)/2, len(data)/3); err != nil {
 		return 0
 	}
+	validate(eraser)
 
 	// Index
-	indexer := New()
+	indexer := NewWithOptions(fuzzOptions)
 	if err := indexer.InsertBytes(0, data); err != nil {
 		return 0
 	}
 	indexer.Index(len(data) / 2)
 
 	// Runes
-	runer := New()
+	runer := NewWithOptions(fuzzOptions)
 	if err := runer.InsertBytes(0, data); err != nil {
 		return 0
 	}
 	runer.Runes()
 
 	// Size
-	sizer := New()
+	sizer := NewWithOptions(fuzzOptions)
 	if err := sizer.InsertBytes(0, data); err != nil {
 		return 0
 	}
 	sizer.Size()
 
 	// Substr
-	substrer := New()
+	substrer := NewWithOptions(fuzzOptions)
 	if err := substrer.InsertBytes(0, data); err != nil {
 		return 0
 	}
 	substrer.Substr(0, len(data)/3)
 
 	// SubstrRunes
-	substrRuner := New()
+	substrRuner := NewWithOptions(fuzzOptions)
 	if err := substrRuner.InsertBytes(0, data); err != nil {
 		return 0
 	}
 	substrRuner.SubstrRunes(0, len(data)/3)
 
 	// SubstrBytes
-	substrByter := New()
+	substrByter := NewWithOptions(fuzzOptions)
 	if err := substrByter.InsertBytes(0, data); err != nil {
 		return 0
 	}
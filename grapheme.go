@@ -0,0 +1,422 @@
+package skiprope
+
+import "unicode"
+
+// gcb is the Grapheme_Cluster_Break property of a rune, as defined in UAX #29.
+// Extended_Pictographic is folded in as a class of its own, as none of the runes that have it have any other class.
+type gcb byte
+
+const (
+	gcbNone gcb = iota // the start of the text
+	gcbOther
+	gcbCR
+	gcbLF
+	gcbControl
+	gcbExtend
+	gcbZWJ
+	gcbRI
+	gcbPrepend
+	gcbSpacingMark
+	gcbL
+	gcbV
+	gcbT
+	gcbLV
+	gcbLVT
+	gcbPict
+)
+
+// graphemeClass returns the Grapheme_Cluster_Break property of a rune.
+func graphemeClass(r rune) gcb {
+	switch {
+	case r == '\r':
+		return gcbCR
+	case r == '\n':
+		return gcbLF
+	case r < 0x20 || r >= 0x7f && r < 0xa0:
+		return gcbControl
+	case r < 0xa9:
+		return gcbOther
+	case r == 0x200d:
+		return gcbZWJ
+	case r >= 0x1f1e6 && r <= 0x1f1ff:
+		return gcbRI
+	case r >= 0xac00 && r <= 0xd7a3:
+		// Hangul syllables are either LV or LVT, depending on whether they have a trailing consonant
+		if (r-0xac00)%28 == 0 {
+			return gcbLV
+		}
+		return gcbLVT
+	case r >= 0x1100 && r <= 0x115f, r >= 0xa960 && r <= 0xa97c:
+		return gcbL
+	case r >= 0x1160 && r <= 0x11a7, r >= 0xd7b0 && r <= 0xd7c6:
+		return gcbV
+	case r >= 0x11a8 && r <= 0x11ff, r >= 0xd7cb && r <= 0xd7fb:
+		return gcbT
+	case r >= 0x1f3fb && r <= 0x1f3ff: // emoji modifiers
+		return gcbExtend
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Other_Grapheme_Extend):
+		return gcbExtend
+	case unicode.In(r, prepend, unicode.Prepended_Concatenation_Mark):
+		return gcbPrepend
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp, unicode.Cs):
+		return gcbControl
+	case r == 0x0e33 || r == 0x0eb3:
+		return gcbSpacingMark
+	case unicode.Is(unicode.Mc, r) && !unicode.Is(notSpacingMark, r):
+		return gcbSpacingMark
+	case unicode.Is(extendedPictographic, r):
+		return gcbPict
+	}
+	return gcbOther
+}
+
+// graphemeBreaker finds the extended grapheme cluster boundaries in a sequence of runes, following the rules of UAX #29.
+// The zero value is ready to use at the start of a text.
+type graphemeBreaker struct {
+	last gcb
+	ri   bool // whether the runes before are an odd number of regional indicators
+	pict byte // whether the runes before are ExtPict Extend* (pictExtend) or ExtPict Extend* ZWJ (pictZWJ)
+	conj byte // whether the runes before are an Indic consonant followed by extenders (conjConsonant) and at least one linker (conjLinked)
+}
+
+const (
+	pictExtend = 1 + iota
+	pictZWJ
+)
+
+const (
+	conjConsonant = 1 + iota
+	conjLinked
+)
+
+// next reports whether there is a grapheme cluster boundary before the rune, and moves the breaker past it.
+func (g *graphemeBreaker) next(r rune) (boundary bool) {
+	c := graphemeClass(r)
+	switch {
+	case g.last == gcbNone: // GB1
+		boundary = true
+	case g.last == gcbCR && c == gcbLF: // GB3
+		boundary = false
+	case g.last == gcbCR || g.last == gcbLF || g.last == gcbControl: // GB4
+		boundary = true
+	case c == gcbCR || c == gcbLF || c == gcbControl: // GB5
+		boundary = true
+	case g.last == gcbL && (c == gcbL || c == gcbV || c == gcbLV || c == gcbLVT): // GB6
+		boundary = false
+	case (g.last == gcbLV || g.last == gcbV) && (c == gcbV || c == gcbT): // GB7
+		boundary = false
+	case (g.last == gcbLVT || g.last == gcbT) && c == gcbT: // GB8
+		boundary = false
+	case c == gcbExtend || c == gcbZWJ || c == gcbSpacingMark: // GB9, GB9a
+		boundary = false
+	case g.last == gcbPrepend: // GB9b
+		boundary = false
+	case g.conj == conjLinked && unicode.Is(incbConsonant, r): // GB9c
+		boundary = false
+	case g.pict == pictZWJ && c == gcbPict: // GB11
+		boundary = false
+	case g.last == gcbRI && c == gcbRI && g.ri: // GB12, GB13
+		boundary = false
+	default: // GB999
+		boundary = true
+	}
+
+	switch {
+	case c == gcbPict:
+		g.pict = pictExtend
+	case c == gcbExtend && g.pict == pictExtend:
+	case c == gcbZWJ && g.pict == pictExtend:
+		g.pict = pictZWJ
+	default:
+		g.pict = 0
+	}
+
+	// InCB=Extend is approximated by Extend and ZWJ, which it is a subset of.
+	switch {
+	case unicode.Is(incbConsonant, r):
+		g.conj = conjConsonant
+	case g.conj != 0 && unicode.Is(incbLinker, r):
+		g.conj = conjLinked
+	case g.conj != 0 && (c == gcbExtend || c == gcbZWJ):
+	default:
+		g.conj = 0
+	}
+
+	g.ri = c == gcbRI && !(g.last == gcbRI && g.ri)
+	g.last = c
+	return boundary
+}
+
+// NextGrapheme returns the point of the first extended grapheme cluster boundary after the given point, which is the end of
+// the cluster the point is in. At the end of the rope, the end of the rope is returned. If the point is out of bounds, -1 is returned.
+//
+// Clusters are what a user would think of as a character, such as a letter with combining diacritics or an emoji ZWJ sequence,
+// and are found with the rules of UAX #29.
+func (r *Rope) NextGrapheme(point int) int {
+	if point < 0 || point > r.runes {
+		return -1
+	}
+	if point == r.runes {
+		return point
+	}
+
+	var g graphemeBreaker
+	start := r.graphemeStart(point + 1)
+	s := NewScannerAt(r, start)
+	for at := start; at < r.runes; at++ {
+		char, _, err := s.ReadRune()
+		if err != nil {
+			break
+		}
+		if g.next(char) && at > point {
+			return at
+		}
+	}
+	return r.runes
+}
+
+// PrevGrapheme returns the point of the last extended grapheme cluster boundary before the given point, which is the start of
+// the cluster before the point. At the start of the rope, 0 is returned. If the point is out of bounds, -1 is returned.
+func (r *Rope) PrevGrapheme(point int) int {
+	if point < 0 || point > r.runes {
+		return -1
+	}
+
+	// look for a boundary in (start, end], moving further back until one is found.
+	for end := point - 1; end > 0; {
+		var g graphemeBreaker
+		start := r.graphemeStart(end)
+		s := NewScannerAt(r, start)
+		boundary := -1
+		for at := start; at <= end; at++ {
+			char, _, err := s.ReadRune()
+			if err != nil {
+				break
+			}
+			// the breaker does not know what is before the start, so it cannot tell whether there is a boundary there.
+			if g.next(char) && (at > start || start == 0) {
+				boundary = at
+			}
+		}
+		if boundary >= 0 {
+			return boundary
+		}
+		end = start
+	}
+	return 0
+}
+
+// GraphemeCount returns the number of extended grapheme clusters which overlap the runes between the two points.
+// This is the number of times NextGrapheme has to be called to get from pointA to pointB.
+// If the points are out of bounds or pointA > pointB, -1 is returned.
+func (r *Rope) GraphemeCount(pointA, pointB int) int {
+	if pointA < 0 || pointB > r.runes || pointA > pointB {
+		return -1
+	}
+	if pointA == pointB {
+		return 0
+	}
+
+	var g graphemeBreaker
+	start := r.graphemeStart(pointA + 1)
+	s := NewScannerAt(r, start)
+	count := 1
+	for at := start; at < pointB; at++ {
+		char, _, err := s.ReadRune()
+		if err != nil {
+			break
+		}
+		if g.next(char) && at > pointA {
+			count++
+		}
+	}
+	return count
+}
+
+// graphemeStart returns a point before the given point from which a graphemeBreaker finds the right boundaries
+// after the point it starts at. Every boundary after that point, up to and including the given point, is then found.
+//
+// The state of a breaker after most runes does not depend on the runes before, so it is enough to go back to the last such rune,
+// or to the start of the rope. Only regional indicators, extenders and joiners carry state over.
+func (r *Rope) graphemeStart(point int) int {
+	s := NewReverseScanner(r, point)
+	for point > 0 {
+		char, _, err := s.ReadRune()
+		if err != nil {
+			break
+		}
+		point--
+		if c := graphemeClass(char); c != gcbRI && c != gcbExtend && c != gcbZWJ {
+			break
+		}
+	}
+	return point
+}
+
+// prepend holds the runes with Grapheme_Cluster_Break=Prepend, apart from the prepended concatenation marks.
+var prepend = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0d4e, Hi: 0x0d4e, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x111c2, Hi: 0x111c3, Stride: 1},
+		{Lo: 0x1193f, Hi: 0x1193f, Stride: 1},
+		{Lo: 0x11941, Hi: 0x11941, Stride: 1},
+		{Lo: 0x11a3a, Hi: 0x11a3a, Stride: 1},
+		{Lo: 0x11a84, Hi: 0x11a89, Stride: 1},
+		{Lo: 0x11d46, Hi: 0x11d46, Stride: 1},
+		{Lo: 0x11f02, Hi: 0x11f02, Stride: 1},
+	},
+}
+
+// notSpacingMark holds the spacing combining marks (Mc) which are not Grapheme_Cluster_Break=SpacingMark.
+var notSpacingMark = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x102b, Hi: 0x102c, Stride: 1},
+		{Lo: 0x1038, Hi: 0x1038, Stride: 1},
+		{Lo: 0x1062, Hi: 0x1064, Stride: 1},
+		{Lo: 0x1067, Hi: 0x106d, Stride: 1},
+		{Lo: 0x1083, Hi: 0x1083, Stride: 1},
+		{Lo: 0x1087, Hi: 0x108c, Stride: 1},
+		{Lo: 0x108f, Hi: 0x108f, Stride: 1},
+		{Lo: 0x109a, Hi: 0x109c, Stride: 1},
+		{Lo: 0x1a61, Hi: 0x1a61, Stride: 1},
+		{Lo: 0x1a63, Hi: 0x1a64, Stride: 1},
+		{Lo: 0xaa7b, Hi: 0xaa7b, Stride: 1},
+		{Lo: 0xaa7d, Hi: 0xaa7d, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x11720, Hi: 0x11721, Stride: 1},
+	},
+}
+
+// incbLinker holds the runes with Indic_Conjunct_Break=Linker.
+var incbLinker = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x094d, Hi: 0x094d, Stride: 1},
+		{Lo: 0x09cd, Hi: 0x09cd, Stride: 1},
+		{Lo: 0x0acd, Hi: 0x0acd, Stride: 1},
+		{Lo: 0x0b4d, Hi: 0x0b4d, Stride: 1},
+		{Lo: 0x0c4d, Hi: 0x0c4d, Stride: 1},
+		{Lo: 0x0d4d, Hi: 0x0d4d, Stride: 1},
+	},
+}
+
+// incbConsonant holds the runes with Indic_Conjunct_Break=Consonant.
+var incbConsonant = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0915, Hi: 0x0939, Stride: 1},
+		{Lo: 0x0958, Hi: 0x095f, Stride: 1},
+		{Lo: 0x0978, Hi: 0x097f, Stride: 1},
+		{Lo: 0x0995, Hi: 0x09a8, Stride: 1},
+		{Lo: 0x09aa, Hi: 0x09b0, Stride: 1},
+		{Lo: 0x09b2, Hi: 0x09b2, Stride: 1},
+		{Lo: 0x09b6, Hi: 0x09b9, Stride: 1},
+		{Lo: 0x09dc, Hi: 0x09dd, Stride: 1},
+		{Lo: 0x09df, Hi: 0x09df, Stride: 1},
+		{Lo: 0x09f0, Hi: 0x09f1, Stride: 1},
+		{Lo: 0x0a95, Hi: 0x0aa8, Stride: 1},
+		{Lo: 0x0aaa, Hi: 0x0ab0, Stride: 1},
+		{Lo: 0x0ab2, Hi: 0x0ab3, Stride: 1},
+		{Lo: 0x0ab5, Hi: 0x0ab9, Stride: 1},
+		{Lo: 0x0af9, Hi: 0x0af9, Stride: 1},
+		{Lo: 0x0b15, Hi: 0x0b28, Stride: 1},
+		{Lo: 0x0b2a, Hi: 0x0b30, Stride: 1},
+		{Lo: 0x0b32, Hi: 0x0b33, Stride: 1},
+		{Lo: 0x0b35, Hi: 0x0b39, Stride: 1},
+		{Lo: 0x0b5c, Hi: 0x0b5d, Stride: 1},
+		{Lo: 0x0b5f, Hi: 0x0b5f, Stride: 1},
+		{Lo: 0x0b71, Hi: 0x0b71, Stride: 1},
+		{Lo: 0x0c15, Hi: 0x0c28, Stride: 1},
+		{Lo: 0x0c2a, Hi: 0x0c39, Stride: 1},
+		{Lo: 0x0c58, Hi: 0x0c5a, Stride: 1},
+		{Lo: 0x0d15, Hi: 0x0d3a, Stride: 1},
+	},
+}
+
+// extendedPictographic holds the runes with the Extended_Pictographic property, which the unicode package does not provide.
+var extendedPictographic = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00a9, Hi: 0x00a9, Stride: 1},
+		{Lo: 0x00ae, Hi: 0x00ae, Stride: 1},
+		{Lo: 0x203c, Hi: 0x203c, Stride: 1},
+		{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+		{Lo: 0x2122, Hi: 0x2122, Stride: 1},
+		{Lo: 0x2139, Hi: 0x2139, Stride: 1},
+		{Lo: 0x2194, Hi: 0x2199, Stride: 1},
+		{Lo: 0x21a9, Hi: 0x21aa, Stride: 1},
+		{Lo: 0x231a, Hi: 0x231b, Stride: 1},
+		{Lo: 0x2328, Hi: 0x2328, Stride: 1},
+		{Lo: 0x2388, Hi: 0x2388, Stride: 1},
+		{Lo: 0x23cf, Hi: 0x23cf, Stride: 1},
+		{Lo: 0x23e9, Hi: 0x23f3, Stride: 1},
+		{Lo: 0x23f8, Hi: 0x23fa, Stride: 1},
+		{Lo: 0x24c2, Hi: 0x24c2, Stride: 1},
+		{Lo: 0x25aa, Hi: 0x25ab, Stride: 1},
+		{Lo: 0x25b6, Hi: 0x25b6, Stride: 1},
+		{Lo: 0x25c0, Hi: 0x25c0, Stride: 1},
+		{Lo: 0x25fb, Hi: 0x25fe, Stride: 1},
+		{Lo: 0x2600, Hi: 0x2605, Stride: 1},
+		{Lo: 0x2607, Hi: 0x2612, Stride: 1},
+		{Lo: 0x2614, Hi: 0x2685, Stride: 1},
+		{Lo: 0x2690, Hi: 0x2705, Stride: 1},
+		{Lo: 0x2708, Hi: 0x2712, Stride: 1},
+		{Lo: 0x2714, Hi: 0x2714, Stride: 1},
+		{Lo: 0x2716, Hi: 0x2716, Stride: 1},
+		{Lo: 0x271d, Hi: 0x271d, Stride: 1},
+		{Lo: 0x2721, Hi: 0x2721, Stride: 1},
+		{Lo: 0x2728, Hi: 0x2728, Stride: 1},
+		{Lo: 0x2733, Hi: 0x2734, Stride: 1},
+		{Lo: 0x2744, Hi: 0x2744, Stride: 1},
+		{Lo: 0x2747, Hi: 0x2747, Stride: 1},
+		{Lo: 0x274c, Hi: 0x274c, Stride: 1},
+		{Lo: 0x274e, Hi: 0x274e, Stride: 1},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2757, Hi: 0x2757, Stride: 1},
+		{Lo: 0x2763, Hi: 0x2767, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x27a1, Hi: 0x27a1, Stride: 1},
+		{Lo: 0x27b0, Hi: 0x27b0, Stride: 1},
+		{Lo: 0x27bf, Hi: 0x27bf, Stride: 1},
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2b05, Hi: 0x2b07, Stride: 1},
+		{Lo: 0x2b1b, Hi: 0x2b1c, Stride: 1},
+		{Lo: 0x2b50, Hi: 0x2b50, Stride: 1},
+		{Lo: 0x2b55, Hi: 0x2b55, Stride: 1},
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+		{Lo: 0x303d, Hi: 0x303d, Stride: 1},
+		{Lo: 0x3297, Hi: 0x3297, Stride: 1},
+		{Lo: 0x3299, Hi: 0x3299, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1f0ff, Stride: 1},
+		{Lo: 0x1f10d, Hi: 0x1f10f, Stride: 1},
+		{Lo: 0x1f12f, Hi: 0x1f12f, Stride: 1},
+		{Lo: 0x1f16c, Hi: 0x1f171, Stride: 1},
+		{Lo: 0x1f17e, Hi: 0x1f17f, Stride: 1},
+		{Lo: 0x1f18e, Hi: 0x1f18e, Stride: 1},
+		{Lo: 0x1f191, Hi: 0x1f19a, Stride: 1},
+		{Lo: 0x1f1ad, Hi: 0x1f1e5, Stride: 1},
+		{Lo: 0x1f201, Hi: 0x1f20f, Stride: 1},
+		{Lo: 0x1f21a, Hi: 0x1f21a, Stride: 1},
+		{Lo: 0x1f22f, Hi: 0x1f22f, Stride: 1},
+		{Lo: 0x1f232, Hi: 0x1f23a, Stride: 1},
+		{Lo: 0x1f23c, Hi: 0x1f23f, Stride: 1},
+		{Lo: 0x1f249, Hi: 0x1f3fa, Stride: 1},
+		{Lo: 0x1f400, Hi: 0x1f53d, Stride: 1},
+		{Lo: 0x1f546, Hi: 0x1f64f, Stride: 1},
+		{Lo: 0x1f680, Hi: 0x1f6ff, Stride: 1},
+		{Lo: 0x1f774, Hi: 0x1f77f, Stride: 1},
+		{Lo: 0x1f7d5, Hi: 0x1f7ff, Stride: 1},
+		{Lo: 0x1f80c, Hi: 0x1f80f, Stride: 1},
+		{Lo: 0x1f848, Hi: 0x1f84f, Stride: 1},
+		{Lo: 0x1f85a, Hi: 0x1f85f, Stride: 1},
+		{Lo: 0x1f888, Hi: 0x1f88f, Stride: 1},
+		{Lo: 0x1f8ae, Hi: 0x1f8ff, Stride: 1},
+		{Lo: 0x1f90c, Hi: 0x1f93a, Stride: 1},
+		{Lo: 0x1f93c, Hi: 0x1f945, Stride: 1},
+		{Lo: 0x1f947, Hi: 0x1faff, Stride: 1},
+		{Lo: 0x1fc00, Hi: 0x1fffd, Stride: 1},
+	},
+	LatinOffset: 2,
+}
@@ -0,0 +1,130 @@
+package skiprope
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// graphemeTests are taken from GraphemeBreakTest.txt, along with a few examples of what users would expect.
+var graphemeTests = []struct {
+	s        string
+	expected []string
+}{
+	{"", nil},
+	{"abc", []string{"a", "b", "c"}},
+	{"\r\n\n\r", []string{"\r\n", "\n", "\r"}},                 // GB3, GB4
+	{"a\u0308b", []string{"a\u0308", "b"}},                     // GB9
+	{"\u0308\u0308", []string{"\u0308\u0308"}},                 // GB9 at the start
+	{"\n\u0308", []string{"\n", "\u0308"}},                     // GB4
+	{"a\u0903b", []string{"a\u0903", "b"}},                     // GB9a
+	{"\u0600a", []string{"\u0600a"}},                           // GB9b
+	{"\u0600\n", []string{"\u0600", "\n"}},                     // GB5
+	{"\u1100\u1161\u11a8", []string{"\u1100\u1161\u11a8"}},     // GB6, GB7
+	{"\uac01\u11a8\u1100", []string{"\uac01\u11a8", "\u1100"}}, // GB8
+	{"\uac00\u1161\uac00", []string{"\uac00\u1161", "\uac00"}}, // GB7
+	{"\u0915\u094d\u0924", []string{"\u0915\u094d\u0924"}},     // GB9c
+	{"\u0915\u094d\u200d\u0924", []string{"\u0915\u094d\u200d\u0924"}},
+	{"\u0915\u0924", []string{"\u0915", "\u0924"}},
+	{"a\u094d\u0924", []string{"a\u094d", "\u0924"}},
+	{"👩\u200d👩\u200d👧", []string{"👩\u200d👩\u200d👧"}}, // GB11
+	{"\u263a\u0308\u200d\u2708", []string{"\u263a\u0308\u200d\u2708"}},
+	{"a\u200d👍", []string{"a\u200d", "👍"}},
+	{"👍🏽👍", []string{"👍🏽", "👍"}},
+	{"🇦🇧🇨", []string{"🇦🇧", "🇨"}}, // GB12, GB13
+	{"a🇦🇧🇨🇩b", []string{"a", "🇦🇧", "🇨🇩", "b"}},
+	{"🇦\u200d🇧🇨", []string{"🇦\u200d", "🇧🇨"}},
+	{"e\u0301 你好", []string{"e\u0301", " ", "你", "好"}},
+}
+
+func graphemeSplit(s string) (retVal []string) {
+	var g graphemeBreaker
+	start := 0
+	for i, r := range s {
+		if g.next(r) && i > 0 {
+			retVal = append(retVal, s[start:i])
+			start = i
+		}
+	}
+	if start < len(s) {
+		retVal = append(retVal, s[start:])
+	}
+	return retVal
+}
+
+func TestGraphemeBreaker(t *testing.T) {
+	for _, gt := range graphemeTests {
+		assert.Equal(t, gt.expected, graphemeSplit(gt.s), "%+q", gt.s)
+	}
+}
+
+func TestRope_Graphemes_Cursor(t *testing.T) {
+	assert := assert.New(t)
+	alphabet := []string{"a", " ", "\r", "\n", "你", "\u0301", "\u0308", "\u200d", "👩", "🏽", "🇦", "🇧", "\u1100", "\u1161", "가", "क", "\u094d", "\u0600"}
+	rnd := rand.New(rand.NewSource(1337))
+	var buf []string
+	for i := 0; i < 1000; i++ {
+		buf = append(buf, alphabet[rnd.Intn(len(alphabet))])
+	}
+	// long runs of extenders and regional indicators
+	buf = append(buf, strings.Repeat("\u0308", 100), strings.Repeat("🇦", 101), "a")
+	s := strings.Join(buf, "")
+	r := NewFromBytes([]byte(s))
+
+	boundaries := []int{0}
+	for _, cluster := range graphemeSplit(s) {
+		boundaries = append(boundaries, boundaries[len(boundaries)-1]+utf8.RuneCountInString(cluster))
+	}
+	n := r.Runes()
+	assert.Equal(n, boundaries[len(boundaries)-1])
+
+	b := 0 // boundaries[b] <= point < boundaries[b+1]
+	for point := 0; point <= n; point++ {
+		if b+1 < len(boundaries) && boundaries[b+1] <= point {
+			b++
+		}
+		next := n
+		if b+1 < len(boundaries) {
+			next = boundaries[b+1]
+		}
+		assert.Equal(next, r.NextGrapheme(point), "NextGrapheme(%d)", point)
+
+		prev := boundaries[b]
+		if prev == point && b > 0 {
+			prev = boundaries[b-1]
+		}
+		assert.Equal(prev, r.PrevGrapheme(point), "PrevGrapheme(%d)", point)
+	}
+	assert.Equal(len(boundaries)-1, r.GraphemeCount(0, n))
+
+	for i := 0; i < 200; i++ {
+		a := rnd.Intn(n + 1)
+		b := a + rnd.Intn(n-a+1)
+		expected := 0
+		for p := a; p < b; p = r.NextGrapheme(p) {
+			expected++
+		}
+		assert.Equal(expected, r.GraphemeCount(a, b), "GraphemeCount(%d, %d)", a, b)
+	}
+
+	assert.Equal(-1, r.NextGrapheme(-1))
+	assert.Equal(-1, r.PrevGrapheme(n+1))
+	assert.Equal(-1, r.GraphemeCount(2, 1))
+	assert.Equal(0, New().NextGrapheme(0))
+	assert.Equal(0, New().PrevGrapheme(0))
+}
+
+func TestRope_Graphemes_Combining(t *testing.T) {
+	assert := assert.New(t)
+	r := New()
+	r.Insert(0, "cafe\u0301 👩\u200d👩\u200d👧!")
+	assert.Equal(5, r.NextGrapheme(3))
+	assert.Equal(3, r.PrevGrapheme(5))
+	assert.Equal(3, r.PrevGrapheme(4))
+	assert.Equal(11, r.NextGrapheme(6))
+	assert.Equal(6, r.PrevGrapheme(11))
+	assert.Equal(7, r.GraphemeCount(0, r.Runes()))
+}
@@ -0,0 +1,254 @@
+package skiprope
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrNoUndo        = errors.New("Nothing to undo")
+	ErrNoRedo        = errors.New("Nothing to redo")
+	ErrInTransaction = errors.New("Cannot undo or redo in the middle of a transaction")
+)
+
+// History records the edits made to a Rope, so that they may be undone and redone.
+//
+// Edits are grouped into transactions. Each call to Undo or Redo undoes or redoes a whole transaction. By default each edit
+// is a transaction of its own, except for consecutive insertions (such as typed characters), which are coalesced
+// into one transaction as long as they are made within CoalesceInterval of each other, and the transaction is smaller than CoalesceSize.
+type History struct {
+	r *Rope
+
+	// Limit is the maximum number of transactions that can be undone. When the limit is exceeded, the oldest transactions are forgotten.
+	// A Limit of 0 means there is no limit.
+	Limit int
+
+	// MaxBytes is the maximum number of bytes of text that are kept by the history. When it is exceeded, the oldest transactions are forgotten.
+	// A MaxBytes of 0 means there is no limit.
+	MaxBytes int
+
+	// CoalesceInterval is the maximum duration between two insertions for them to be coalesced into one transaction.
+	// A CoalesceInterval of 0 turns off coalescing.
+	CoalesceInterval time.Duration
+
+	// CoalesceSize is the maximum size in bytes of a coalesced transaction.
+	CoalesceSize int
+
+	undo, redo []*transaction
+	size       int // number of bytes kept in undo and redo
+
+	depth    int  // depth of nested transactions
+	applying bool // if true, edits are not recorded
+	coalesce bool // if true, the next insertion may be coalesced into the last transaction
+	now      func() time.Time
+}
+
+// edit is a single recorded edit. The data is either the inserted or the erased bytes.
+type edit struct {
+	point  int
+	data   []byte
+	insert bool
+}
+
+// transaction is a group of edits that are undone and redone together.
+type transaction struct {
+	edits []edit
+	size  int       // number of bytes kept in the edits
+	last  time.Time // when the last edit was made
+}
+
+// NewHistory creates a new History which records the edits made to the rope.
+// A rope only has one History - creating a new History replaces the previous one.
+func NewHistory(r *Rope) *History {
+	h := &History{
+		r:                r,
+		Limit:            1000,
+		CoalesceInterval: time.Second,
+		CoalesceSize:     BucketSize,
+		now:              time.Now,
+	}
+	r.history = h
+	return h
+}
+
+// Detach stops the History from recording the edits made to the rope.
+func (h *History) Detach() {
+	if h.r.history == h {
+		h.r.history = nil
+	}
+}
+
+// Begin starts a transaction. All edits until the matching call to Commit are undone and redone together.
+// Transactions may be nested, in which case the outermost transaction is the one that counts.
+func (h *History) Begin() {
+	if h.depth == 0 {
+		h.undo = append(h.undo, &transaction{last: h.now()})
+		h.coalesce = false
+	}
+	h.depth++
+}
+
+// Commit ends a transaction started with Begin.
+func (h *History) Commit() {
+	if h.depth == 0 {
+		return
+	}
+	h.depth--
+	if h.depth == 0 && len(h.undo) > 0 && len(h.undo[len(h.undo)-1].edits) == 0 {
+		// empty transactions are not worth keeping
+		h.undo = h.undo[:len(h.undo)-1]
+	}
+}
+
+// CanUndo returns true if there is a transaction to undo.
+func (h *History) CanUndo() bool { return len(h.undo) > 0 && h.depth == 0 }
+
+// CanRedo returns true if there is a transaction to redo.
+func (h *History) CanRedo() bool { return len(h.redo) > 0 && h.depth == 0 }
+
+// Undo undoes the last transaction.
+func (h *History) Undo() (err error) {
+	if h.depth > 0 {
+		return ErrInTransaction
+	}
+	if len(h.undo) == 0 {
+		return ErrNoUndo
+	}
+	t := h.undo[len(h.undo)-1]
+
+	h.applying = true
+	defer func() { h.applying = false }()
+	for i := len(t.edits) - 1; i >= 0; i-- {
+		e := t.edits[i]
+		if e.insert {
+			err = h.r.EraseAt(e.point, utf8.RuneCount(e.data))
+		} else {
+			err = h.r.InsertBytes(e.point, e.data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, t)
+	h.coalesce = false
+	return nil
+}
+
+// Redo redoes the last undone transaction.
+func (h *History) Redo() (err error) {
+	if h.depth > 0 {
+		return ErrInTransaction
+	}
+	if len(h.redo) == 0 {
+		return ErrNoRedo
+	}
+	t := h.redo[len(h.redo)-1]
+
+	h.applying = true
+	defer func() { h.applying = false }()
+	for _, e := range t.edits {
+		if e.insert {
+			err = h.r.InsertBytes(e.point, e.data)
+		} else {
+			err = h.r.EraseAt(e.point, utf8.RuneCount(e.data))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, t)
+	h.coalesce = false
+	return nil
+}
+
+// Reset forgets all the recorded transactions.
+func (h *History) Reset() {
+	h.undo = nil
+	h.redo = nil
+	h.size = 0
+	h.depth = 0
+	h.coalesce = false
+}
+
+func (h *History) recordInsert(point int, data []byte) {
+	if h.applying || len(data) == 0 {
+		return
+	}
+	now := h.now()
+
+	// consecutive insertions are coalesced
+	if h.coalesce && h.depth == 0 && len(h.undo) > 0 {
+		t := h.undo[len(h.undo)-1]
+		e := &t.edits[len(t.edits)-1]
+		if e.insert && e.point+utf8.RuneCount(e.data) == point &&
+			now.Sub(t.last) <= h.CoalesceInterval && t.size+len(data) <= h.CoalesceSize {
+			e.data = append(e.data, data...)
+			t.size += len(data)
+			t.last = now
+			h.size += len(data)
+			h.enforceLimits()
+			return
+		}
+	}
+
+	h.record(edit{point: point, data: append([]byte(nil), data...), insert: true}, now)
+	h.coalesce = h.depth == 0 && h.CoalesceInterval > 0
+}
+
+func (h *History) recordErase(point int, data []byte) {
+	if h.applying || len(data) == 0 {
+		return
+	}
+	h.record(edit{point: point, data: data}, h.now())
+	h.coalesce = false
+}
+
+// recordReplace records a replacement as an erasure followed by an insertion, in one transaction.
+func (h *History) recordReplace(point int, erased, inserted []byte) {
+	switch {
+	case h.applying:
+	case len(erased) == 0:
+		h.recordInsert(point, inserted)
+	case len(inserted) == 0:
+		h.recordErase(point, erased)
+	default:
+		h.Begin()
+		h.recordErase(point, erased)
+		h.recordInsert(point, inserted)
+		h.Commit()
+	}
+}
+
+// record adds the edit to the current transaction, or to a new one if there is no transaction.
+func (h *History) record(e edit, now time.Time) {
+	for _, t := range h.redo {
+		h.size -= t.size
+	}
+	h.redo = nil
+
+	if h.depth == 0 {
+		h.undo = append(h.undo, &transaction{last: now})
+	}
+	t := h.undo[len(h.undo)-1]
+	t.edits = append(t.edits, e)
+	t.size += len(e.data)
+	t.last = now
+	h.size += len(e.data)
+	h.enforceLimits()
+}
+
+// enforceLimits forgets the oldest transactions until the history is within its limits.
+// The current transaction is never forgotten.
+func (h *History) enforceLimits() {
+	var drop int
+	for drop < len(h.undo)-1 && ((h.Limit > 0 && len(h.undo)-drop > h.Limit) || (h.MaxBytes > 0 && h.size > h.MaxBytes)) {
+		h.size -= h.undo[drop].size
+		drop++
+	}
+	if drop > 0 {
+		h.undo = append(h.undo[:0], h.undo[drop:]...)
+	}
+}
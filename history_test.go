@@ -0,0 +1,128 @@
+package skiprope
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	r := New()
+	h := NewHistory(r)
+	h.CoalesceInterval = 0
+
+	r.Insert(0, "Hello World")
+	r.Insert(5, ",")
+	r.EraseAt(0, 7)
+	r.InsertAtByte(0, []byte("你好"))
+	r.EraseBytes(3, 3) // erase "好"
+	assert.Equal(t, "你World", r.String())
+
+	expected := []string{"你好World", "World", "Hello, World", "Hello World", ""}
+	for _, e := range expected {
+		if err := h.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, e, r.String())
+	}
+	assert.Equal(t, ErrNoUndo, h.Undo())
+
+	for i := len(expected) - 2; i >= 0; i-- {
+		if err := h.Redo(); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected[i], r.String())
+	}
+	if err := h.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "你World", r.String())
+	assert.Equal(t, ErrNoRedo, h.Redo())
+
+	// a new edit clears the redo stack
+	h.Undo()
+	r.Insert(0, "!")
+	assert.False(t, h.CanRedo())
+	validRope(t, r)
+}
+
+func TestHistory_Transaction(t *testing.T) {
+	r := New()
+	h := NewHistory(r)
+	r.Insert(0, "Hello World")
+
+	h.Begin()
+	r.EraseAt(0, 5)
+	h.Begin() // nested
+	r.Insert(0, "Goodbye")
+	h.Commit()
+	assert.Equal(t, ErrInTransaction, h.Undo())
+	h.Commit()
+	assert.Equal(t, "Goodbye World", r.String())
+
+	if err := h.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Hello World", r.String())
+	if err := h.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Goodbye World", r.String())
+}
+
+func TestHistory_Coalesce(t *testing.T) {
+	r := New()
+	h := NewHistory(r)
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	// typing
+	for i, c := range "Hello" {
+		r.Insert(i, string(c))
+	}
+	// a pause
+	now = now.Add(2 * h.CoalesceInterval)
+	for i, c := range " World" {
+		r.Insert(5+i, string(c))
+	}
+	assert.Equal(t, 2, len(h.undo))
+
+	h.Undo()
+	assert.Equal(t, "Hello", r.String())
+	h.Undo()
+	assert.Equal(t, "", r.String())
+
+	// size based
+	h.Reset()
+	h.CoalesceSize = 3
+	for i, c := range "abcdefg" {
+		r.Insert(i, string(c))
+	}
+	assert.Equal(t, 3, len(h.undo))
+	h.Undo()
+	assert.Equal(t, "abcdef", r.String())
+}
+
+func TestHistory_Limit(t *testing.T) {
+	r := New()
+	h := NewHistory(r)
+	h.CoalesceInterval = 0
+	h.Limit = 3
+	for i := 0; i < 10; i++ {
+		r.Insert(i, "a")
+	}
+	assert.Equal(t, 3, len(h.undo))
+
+	h.Limit = 0
+	h.MaxBytes = 5
+	for i := 0; i < 10; i++ {
+		r.Insert(i, "b")
+	}
+	assert.Equal(t, 5, len(h.undo))
+	assert.Equal(t, 5, h.size)
+
+	h.Detach()
+	r.Insert(0, "c")
+	assert.Equal(t, 5, len(h.undo))
+}
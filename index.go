@@ -0,0 +1,121 @@
+package skiprope
+
+// searchSize is the number of bytes that are searched at a time by IndexOf and LastIndexOf.
+const searchSize = 64 * BucketSize
+
+// IndexOf returns the point of the first occurrence of the needle at or after the given point, or -1 if there is none.
+//
+// The rope is searched a window at a time with the Boyer-Moore-Horspool algorithm, so matches that span knots are found
+// without building the whole string.
+func (r *Rope) IndexOf(needle string, from int) int {
+	from = clamp(from, 0, r.runes)
+	if len(needle) == 0 {
+		return from
+	}
+
+	s := skiplist{r: r}
+	k, offset, skippedBytes, err := s.find(from)
+	if err != nil {
+		return -1
+	}
+
+	h := newHorspool(needle)
+	buf := make([]byte, 0, max(searchSize, len(needle)+BucketSize))
+	start := skippedBytes + offset // the byte offset of buf
+	for ; k != nil; k, offset = k.nexts[0].knot, 0 {
+		data := k.data[offset:k.used]
+		if len(buf)+len(data) > cap(buf) {
+			if i := h.index(buf); i >= 0 {
+				return r.RuneOffset(start + i)
+			}
+			// the last len(needle)-1 bytes may be the start of a match
+			keep := min(len(buf), len(needle)-1)
+			start += len(buf) - keep
+			buf = append(buf[:0], buf[len(buf)-keep:]...)
+		}
+		buf = append(buf, data...)
+	}
+	if i := h.index(buf); i >= 0 {
+		return r.RuneOffset(start + i)
+	}
+	return -1
+}
+
+// LastIndexOf returns the point of the last occurrence of the needle which ends at or before the given point, or -1 if there is none.
+func (r *Rope) LastIndexOf(needle string, from int) int {
+	from = clamp(from, 0, r.runes)
+	if len(needle) == 0 {
+		return from
+	}
+
+	h := newHorspool(needle)
+	size := max(searchSize, len(needle)+BucketSize)
+	var buf []byte
+	start := r.ByteOffset(from) // the byte offset of buf
+	for start > 0 {
+		// the knots are only linked forwards, so the window before the current one has to be found from the head.
+		prev := r.SubstrByteRange(start-size, start)
+		start -= len(prev)
+
+		// the first len(needle)-1 bytes of the current window may be the end of a match
+		keep := min(len(buf), len(needle)-1)
+		buf = append(prev, buf[:keep]...)
+		if i := h.lastIndex(buf); i >= 0 {
+			return r.RuneOffset(start + i)
+		}
+	}
+	return -1
+}
+
+// horspool searches for a needle using the Boyer-Moore-Horspool algorithm.
+type horspool struct {
+	needle []byte
+	skip   [256]int // how far to move forwards when the byte under the end of the needle does not match
+	back   [256]int // how far to move backwards when the byte under the start of the needle does not match
+}
+
+func newHorspool(needle string) *horspool {
+	h := &horspool{needle: []byte(needle)}
+	last := len(needle) - 1
+	for i := range h.skip {
+		h.skip[i] = len(needle)
+		h.back[i] = len(needle)
+	}
+	for i := 0; i < last; i++ {
+		h.skip[needle[i]] = last - i
+	}
+	for i := last; i > 0; i-- {
+		h.back[needle[i]] = i
+	}
+	return h
+}
+
+// index returns the index of the first occurrence of the needle in the text, or -1.
+func (h *horspool) index(text []byte) int {
+	last := len(h.needle) - 1
+	for i := 0; i+last < len(text); i += h.skip[text[i+last]] {
+		if h.match(text[i:]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastIndex returns the index of the last occurrence of the needle in the text, or -1.
+func (h *horspool) lastIndex(text []byte) int {
+	for i := len(text) - len(h.needle); i >= 0; i -= h.back[text[i]] {
+		if h.match(text[i:]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (h *horspool) match(text []byte) bool {
+	for j := len(h.needle) - 1; j >= 0; j-- {
+		if text[j] != h.needle[j] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package skiprope
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRope_IndexOf(t *testing.T) {
+	assert := assert.New(t)
+	str := strings.Repeat("Hello World! 你好世界!\n", 300) + "needle in a haystack " + strings.Repeat("Hello World! 你好世界!\n", 300)
+	r := NewFromBytes([]byte(str))
+
+	runeIndex := func(s, needle string) int {
+		i := strings.Index(s, needle)
+		if i < 0 {
+			return -1
+		}
+		return utf8.RuneCountInString(s[:i])
+	}
+
+	assert.Equal(runeIndex(str, "needle"), r.IndexOf("needle", 0))
+	assert.Equal(runeIndex(str, "needle"), r.LastIndexOf("needle", r.Runes()))
+	assert.Equal(-1, r.IndexOf("needle", r.IndexOf("needle", 0)+1))
+	assert.Equal(-1, r.LastIndexOf("needle", r.IndexOf("needle", 0)+5))
+	assert.Equal(-1, r.IndexOf("missing", 0))
+	assert.Equal(-1, r.LastIndexOf("missing", r.Runes()))
+	assert.Equal(10, r.IndexOf("", 10))
+	assert.Equal(10, r.LastIndexOf("", 10))
+
+	// needles longer than the search window
+	long := str[len(str)/2-5000 : len(str)/2+5000]
+	assert.Equal(runeIndex(str, long), r.IndexOf(long, 0))
+	assert.Equal(runeIndex(str, long), r.LastIndexOf(long, r.Runes()))
+
+	runes := []rune(str)
+	rnd := rand.New(rand.NewSource(1337))
+	for i := 0; i < 500; i++ {
+		from := rnd.Intn(len(runes) + 1)
+		a := rnd.Intn(len(runes))
+		needle := string(runes[a : a+min(1+rnd.Intn(100), len(runes)-a)])
+
+		expected := runeIndex(string(runes[from:]), needle)
+		if expected >= 0 {
+			expected += from
+		}
+		assert.Equal(expected, r.IndexOf(needle, from), "IndexOf(%q, %d)", needle, from)
+
+		expected = -1
+		if i := strings.LastIndex(string(runes[:from]), needle); i >= 0 {
+			expected = utf8.RuneCountInString(string(runes[:from])[:i])
+		}
+		assert.Equal(expected, r.LastIndexOf(needle, from), "LastIndexOf(%q, %d)", needle, from)
+	}
+}
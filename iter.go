@@ -0,0 +1,106 @@
+//go:build go1.23
+// +build go1.23
+
+package skiprope
+
+import (
+	"bytes"
+	"iter"
+	"unicode/utf8"
+)
+
+// RunesFrom returns an iterator over the runes of the rope from the given point onwards, yielding each rune with its point.
+// The point is clamped to the bounds of the rope. (Runes is already taken by the rune count.)
+//
+// The rope must not be modified while iterating.
+func (r *Rope) RunesFrom(from int) iter.Seq2[int, rune] {
+	return func(yield func(int, rune) bool) {
+		s := skiplist{r: r}
+		k, offset, _, err := s.find(clamp(from, 0, r.runes))
+		if err != nil {
+			return
+		}
+		for point := clamp(from, 0, r.runes); k != nil; k, offset = k.nexts[0].knot, 0 {
+			for offset < k.used {
+				char, size := utf8.DecodeRune(k.data[offset:k.used])
+				if !yield(point, char) {
+					return
+				}
+				offset += size
+				point++
+			}
+		}
+	}
+}
+
+// Lines returns an iterator over the lines of the rope, yielding each line with its index. Lines are 0-indexed,
+// and do not include the newline. As with LineCount, the text after the last newline is a line, even if it is empty.
+//
+// The rope must not be modified while iterating.
+func (r *Rope) Lines() iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		var buf []byte
+		line := 0
+		for k := &r.Head; k != nil; k = k.nexts[0].knot {
+			data := k.data[:k.used]
+			for {
+				i := bytes.IndexByte(data, '\n')
+				if i < 0 {
+					break
+				}
+				buf = append(buf, data[:i]...)
+				if !yield(line, string(buf)) {
+					return
+				}
+				buf = buf[:0]
+				data = data[i+1:]
+				line++
+			}
+			buf = append(buf, data...)
+		}
+		yield(line, string(buf))
+	}
+}
+
+// Chunks returns an iterator over the contents of the knots of the rope, in order. The slices are not copied,
+// so they must not be modified, nor used after the rope is modified. Empty knots are skipped.
+func (r *Rope) Chunks() iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for k := &r.Head; k != nil; k = k.nexts[0].knot {
+			if k.used == 0 {
+				continue
+			}
+			if !yield(k.data[:k.used]) {
+				return
+			}
+		}
+	}
+}
+
+// Graphemes returns an iterator over the extended grapheme clusters of the rope (what a user would think of as a character),
+// yielding each cluster with the point of its first rune. Clusters are found with the rules of UAX #29.
+//
+// The rope must not be modified while iterating.
+func (r *Rope) Graphemes() iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		var g graphemeBreaker
+		var buf []byte
+		start, point := 0, 0
+		for k := &r.Head; k != nil; k = k.nexts[0].knot {
+			for offset := 0; offset < k.used; point++ {
+				char, size := utf8.DecodeRune(k.data[offset:k.used])
+				if g.next(char) && len(buf) > 0 {
+					if !yield(start, string(buf)) {
+						return
+					}
+					buf, start = buf[:0], point
+				}
+				buf = append(buf, k.data[offset:offset+size]...)
+				offset += size
+			}
+		}
+		if len(buf) > 0 {
+			yield(start, string(buf))
+		}
+	}
+}
@@ -0,0 +1,99 @@
+//go:build go1.23
+// +build go1.23
+
+package skiprope
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var iterText = strings.Repeat("Hello World! 你好世界! 👩\u200d👩\u200d👧 e\u0301\n", 20)
+
+func TestRope_RunesFrom(t *testing.T) {
+	assert := assert.New(t)
+	r := NewFromBytes([]byte(iterText))
+	runes := []rune(iterText)
+
+	for _, from := range []int{-1, 0, 1, 63, 100, len(runes), len(runes) + 1} {
+		var got []rune
+		expected := clamp(from, 0, len(runes))
+		for point, char := range r.RunesFrom(from) {
+			assert.Equal(expected, point)
+			expected++
+			got = append(got, char)
+		}
+		assert.Equal(string(runes[clamp(from, 0, len(runes)):]), string(got), "RunesFrom(%d)", from)
+	}
+
+	// stopping early
+	for point := range r.RunesFrom(10) {
+		if point == 20 {
+			break
+		}
+	}
+}
+
+func TestRope_IterLines(t *testing.T) {
+	assert := assert.New(t)
+	for _, s := range []string{"", "\n", "a\nb", "a\nb\n", iterText} {
+		r := NewFromBytes([]byte(s))
+		var lines []string
+		for i, line := range r.Lines() {
+			assert.Equal(len(lines), i)
+			lines = append(lines, line)
+		}
+		assert.Equal(strings.Split(s, "\n"), lines)
+		assert.Equal(r.LineCount(), len(lines))
+	}
+
+	r := NewFromBytes([]byte(iterText))
+	for i := range r.Lines() {
+		if i == 3 {
+			break
+		}
+	}
+}
+
+func TestRope_Chunks(t *testing.T) {
+	r := NewFromBytes([]byte(iterText))
+	var buf []byte
+	for chunk := range r.Chunks() {
+		assert.NotEmpty(t, chunk)
+		assert.True(t, len(chunk) <= BucketSize)
+		buf = append(buf, chunk...)
+	}
+	assert.Equal(t, iterText, string(buf))
+
+	for range New().Chunks() {
+		t.Error("Expected no chunks in an empty rope")
+	}
+}
+
+func TestRope_Graphemes(t *testing.T) {
+	assert := assert.New(t)
+	for _, gt := range graphemeTests {
+		r := New()
+		r.Insert(0, gt.s)
+		var clusters []string
+		point := 0
+		for start, cluster := range r.Graphemes() {
+			assert.Equal(point, start)
+			point += len([]rune(cluster))
+			clusters = append(clusters, cluster)
+		}
+		assert.Equal(gt.expected, clusters, "%+q", gt.s)
+	}
+
+	// clusters which straddle knots
+	r := NewFromBytes([]byte(iterText))
+	var clusters []string
+	for _, cluster := range r.Graphemes() {
+		clusters = append(clusters, cluster)
+	}
+	assert.Equal(graphemeSplit(iterText), clusters)
+	assert.Contains(clusters, "👩\u200d👩\u200d👧")
+	assert.Contains(clusters, "e\u0301")
+}
@@ -0,0 +1,69 @@
+package skiprope
+
+import "unicode/utf8"
+
+// LineCount returns the number of lines in the rope. An empty rope has one line.
+func (r *Rope) LineCount() int { return r.lines + 1 }
+
+// LineStart returns the point of the first rune of the given line. Lines are 0-indexed.
+// If the line does not exist, -1 is returned.
+func (r *Rope) LineStart(line int) int {
+	if line < 0 || line > r.lines {
+		return -1
+	}
+	if line == 0 {
+		return 0
+	}
+
+	s := skiplist{r: r}
+	k, n, skippedRunes, err := s.findLine(line)
+	if err != nil {
+		return -1
+	}
+
+	// the nth newline is in k
+	for i := 0; i < k.used; {
+		char, size := utf8.DecodeRune(k.data[i:k.used])
+		i += size
+		skippedRunes++
+		if char == '\n' {
+			if n--; n == 0 {
+				break
+			}
+		}
+	}
+	return skippedRunes
+}
+
+// PointToLineCol returns the line and column of the rune at the given point. Both line and column are 0-indexed,
+// and the column is counted in runes.
+// If the point is out of bounds, -1, -1 is returned.
+func (r *Rope) PointToLineCol(at int) (line, col int) {
+	if at < 0 || at > r.runes {
+		return -1, -1
+	}
+
+	s := skiplist{r: r}
+	if _, _, _, err := s.find(at); err != nil {
+		return -1, -1
+	}
+	// the topmost level of the search always starts at the head, so it holds the number of newlines before the point.
+	line = s.s[r.Head.height-1].skippedLines
+	return line, at - r.LineStart(line)
+}
+
+// LineColToPoint returns the point of the given line and column. Both line and column are 0-indexed.
+// If col is beyond the end of the line, the point at the end of the line is returned.
+// If the line does not exist, -1 is returned.
+func (r *Rope) LineColToPoint(line, col int) int {
+	start := r.LineStart(line)
+	if start < 0 || col < 0 {
+		return -1
+	}
+
+	end := r.runes
+	if line < r.lines {
+		end = r.LineStart(line+1) - 1 // do not count the newline
+	}
+	return start + min(col, end-start)
+}
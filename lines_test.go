@@ -0,0 +1,111 @@
+package skiprope
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// naiveLineStarts returns the rune offsets of the start of each line in s
+func naiveLineStarts(s string) []int {
+	retVal := []int{0}
+	var i int
+	for _, r := range s {
+		i++
+		if r == '\n' {
+			retVal = append(retVal, i)
+		}
+	}
+	return retVal
+}
+
+func checkLines(t *testing.T, r *Rope, s string) {
+	starts := naiveLineStarts(s)
+	assert.Equal(t, len(starts), r.LineCount())
+	for i, start := range starts {
+		assert.Equal(t, start, r.LineStart(i), "LineStart(%d)", i)
+	}
+	assert.Equal(t, -1, r.LineStart(len(starts)))
+
+	runes := []rune(s)
+	line := 0
+	for at := 0; at <= len(runes); at++ {
+		if line+1 < len(starts) && at >= starts[line+1] {
+			line++
+		}
+		l, c := r.PointToLineCol(at)
+		assert.Equal(t, line, l, "Line of %d", at)
+		assert.Equal(t, at-starts[line], c, "Col of %d", at)
+		assert.Equal(t, at, r.LineColToPoint(l, c))
+	}
+}
+
+func TestRope_Lines(t *testing.T) {
+	s := `1 Hello World. The first line is intentionally super long as to make sure there are several blocks
+	2
+	3 你好世界. Here is another long வாக்கியம் to make sure that the bucket is exceeded
+	4 Last line!
+	`
+	r := New()
+	if err := r.Insert(0, s); err != nil {
+		t.Fatal(err)
+	}
+	validRope(t, r)
+	checkLines(t, r, s)
+
+	// col beyond the end of the line is clamped
+	assert.Equal(t, r.LineStart(2)-1, r.LineColToPoint(1, 100))
+	assert.Equal(t, -1, r.LineColToPoint(100, 0))
+	l, c := r.PointToLineCol(r.Runes() + 1)
+	assert.Equal(t, -1, l)
+	assert.Equal(t, -1, c)
+
+	// erasing across multiple knots
+	if err := r.EraseAt(50, 60); err != nil {
+		t.Fatal(err)
+	}
+	rs := []rune(s)
+	s = string(rs[:50]) + string(rs[110:])
+	assert.Equal(t, s, r.String())
+	validRope(t, r)
+	checkLines(t, r, s)
+}
+
+func TestRope_LinesRandom(t *testing.T) {
+	const alphabet = "ab\n你\n好 "
+	ar := []rune(alphabet)
+	rnd := rand.New(rand.NewSource(1337))
+
+	r := New()
+	var rs []rune
+	for i := 0; i < 500; i++ {
+		at := rnd.Intn(len(rs) + 1)
+		if rnd.Intn(3) == 0 && len(rs) > 0 {
+			n := rnd.Intn(80)
+			if err := r.EraseAt(at, n); err != nil {
+				t.Fatal(err)
+			}
+			end := min(at+n, len(rs))
+			rs = append(rs[:at:at], rs[end:]...)
+		} else {
+			ins := make([]rune, rnd.Intn(100))
+			for j := range ins {
+				ins[j] = ar[rnd.Intn(len(ar))]
+			}
+			if err := r.InsertRunes(at, ins); err != nil {
+				t.Fatal(err)
+			}
+			rs = append(rs[:at:at], append(ins, rs[at:]...)...)
+		}
+		s := string(rs)
+		if !assert.Equal(t, s, r.String()) {
+			t.FailNow()
+		}
+		assert.Equal(t, len(s), r.Size())
+		assert.Equal(t, strings.Count(s, "\n")+1, r.LineCount())
+	}
+	validRope(t, r)
+	checkLines(t, r, string(rs))
+}
@@ -0,0 +1,200 @@
+package skiprope
+
+// Gravity decides what happens to a mark when text is inserted exactly at the mark.
+type Gravity byte
+
+const (
+	LeftGravity  Gravity = iota // the mark stays before the inserted text
+	RightGravity                // the mark moves to after the inserted text
+)
+
+// Mark is a position in a rope that moves with the edits made to the rope. Cursors, selections and bookmarks are all marks.
+//
+// When text is inserted before the mark, the mark moves forwards. When text before the mark is erased, the mark moves backwards.
+// If the text around the mark is erased, the mark moves to the start of the erased text.
+//
+// Marks are kept in a skiplist of their own, which stores the distance between consecutive marks. An edit only
+// updates the links that span across the edit, so a rope may have many marks.
+type Mark struct {
+	r       *Rope
+	gravity Gravity
+
+	nexts []markLink
+	back  *Mark // the previous mark at the topmost level of this mark
+}
+
+type markLink struct {
+	*Mark
+	skipped int // number of runes between the mark and the next mark
+}
+
+// markList is the skiplist holding all the marks of a rope.
+//
+// The marks are sorted by their key, which is 2*position + gravity. This means that marks with LeftGravity come before
+// marks with RightGravity at the same position.
+type markList struct {
+	head   Mark
+	height int
+}
+
+// markPath is the last mark visited at each height during a search, and its position.
+type markPath struct {
+	marks     [MaxHeight]*Mark
+	positions [MaxHeight]int
+}
+
+func newMarkList() *markList {
+	l := &markList{height: 1}
+	l.head.nexts = make([]markLink, MaxHeight)
+	return l
+}
+
+// NewMark creates a new mark at the given point.
+func (r *Rope) NewMark(point int, gravity Gravity) *Mark {
+	if r.marks == nil {
+		r.marks = newMarkList()
+	}
+	m := &Mark{
+		r:       r,
+		gravity: gravity,
+		nexts:   make([]markLink, r.randInt()),
+	}
+	r.marks.add(m, clamp(point, 0, r.runes))
+	return m
+}
+
+// Pos returns the point of the mark. If the mark has been deleted, -1 is returned.
+func (m *Mark) Pos() (retVal int) {
+	if m.r == nil {
+		return -1
+	}
+	for it := m; it.back != nil; it = it.back {
+		retVal += it.back.nexts[len(it.nexts)-1].skipped
+	}
+	return retVal
+}
+
+// Gravity returns the gravity of the mark.
+func (m *Mark) Gravity() Gravity { return m.gravity }
+
+// Move moves the mark to the given point.
+func (m *Mark) Move(point int) {
+	if m.r == nil {
+		return
+	}
+	m.r.marks.remove(m)
+	m.r.marks.add(m, clamp(point, 0, m.r.runes))
+}
+
+// Delete removes the mark from the rope. The mark is no longer moved by edits.
+func (m *Mark) Delete() {
+	if m.r == nil {
+		return
+	}
+	m.r.marks.remove(m)
+	m.r = nil
+}
+
+// key returns the sort key of a mark at the given position.
+func key(pos int, gravity Gravity) int { return 2*pos + int(gravity) }
+
+// search finds the last mark at each height whose key is less than the given key.
+func (l *markList) search(k int) (retVal markPath) {
+	it := &l.head
+	var pos int
+	for height := l.height - 1; height >= 0; height-- {
+		for next := it.nexts[height]; next.Mark != nil && key(pos+next.skipped, next.gravity) < k; next = it.nexts[height] {
+			pos += next.skipped
+			it = next.Mark
+		}
+		retVal.marks[height] = it
+		retVal.positions[height] = pos
+	}
+	return retVal
+}
+
+// add adds a mark at the given position. A mark with LeftGravity is added before all other marks at the same position,
+// while a mark with RightGravity is added after them.
+func (l *markList) add(m *Mark, pos int) {
+	k := key(pos, LeftGravity)
+	if m.gravity == RightGravity {
+		k = key(pos+1, LeftGravity)
+	}
+	path := l.search(k)
+
+	height := len(m.nexts)
+	for ; l.height < height; l.height++ {
+		path.marks[l.height] = &l.head
+		path.positions[l.height] = 0
+	}
+
+	for i := 0; i < height; i++ {
+		prev := path.marks[i]
+		next := prev.nexts[i]
+		m.nexts[i] = markLink{next.Mark, 0}
+		if next.Mark != nil {
+			m.nexts[i].skipped = path.positions[i] + next.skipped - pos
+			if len(next.nexts)-1 == i {
+				next.back = m
+			}
+		}
+		prev.nexts[i] = markLink{m, pos - path.positions[i]}
+	}
+	m.back = path.marks[height-1]
+}
+
+// remove removes the mark from the list.
+func (l *markList) remove(m *Mark) {
+	prev := m.back
+	for i := len(m.nexts) - 1; i >= 0; i-- {
+		// the previous marks at the lower levels are found by walking forwards from the previous mark of the level above
+		for prev.nexts[i].Mark != m {
+			prev = prev.nexts[i].Mark
+		}
+		next := m.nexts[i]
+		if next.Mark != nil && len(next.nexts)-1 == i {
+			next.back = prev
+		}
+		prev.nexts[i] = markLink{next.Mark, prev.nexts[i].skipped + next.skipped}
+	}
+	m.back = nil
+	for i := range m.nexts {
+		m.nexts[i] = markLink{}
+	}
+}
+
+// inserted moves the marks after an insertion of n runes at the point.
+func (l *markList) inserted(point, n int) {
+	// marks with LeftGravity at the point stay, everything after moves.
+	path := l.search(key(point, RightGravity))
+	for i := 0; i < l.height; i++ {
+		if path.marks[i].nexts[i].Mark != nil {
+			path.marks[i].nexts[i].skipped += n
+		}
+	}
+}
+
+// erased moves the marks after n runes are erased from the point.
+func (l *markList) erased(point, n int) {
+	// the marks within the erased text are moved to the point
+	path := l.search(key(point+1, LeftGravity))
+	var moved []*Mark
+	for {
+		next := path.marks[0].nexts[0]
+		if next.Mark == nil || path.positions[0]+next.skipped > point+n {
+			break
+		}
+		l.remove(next.Mark)
+		moved = append(moved, next.Mark)
+	}
+
+	for i := 0; i < l.height; i++ {
+		if path.marks[i].nexts[i].Mark != nil {
+			path.marks[i].nexts[i].skipped -= n
+		}
+	}
+
+	for _, m := range moved {
+		l.add(m, point)
+	}
+}
@@ -0,0 +1,99 @@
+package skiprope
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMark(t *testing.T) {
+	r := New()
+	r.Insert(0, "Hello World")
+	left := r.NewMark(5, LeftGravity)
+	right := r.NewMark(5, RightGravity)
+	after := r.NewMark(8, LeftGravity)
+
+	r.Insert(5, ",")
+	assert.Equal(t, 5, left.Pos())
+	assert.Equal(t, 6, right.Pos())
+	assert.Equal(t, 9, after.Pos())
+
+	r.Insert(0, "你好 ")
+	assert.Equal(t, 8, left.Pos())
+	assert.Equal(t, 9, right.Pos())
+	assert.Equal(t, 12, after.Pos())
+
+	// erasing around a mark moves it to the start of the erased text
+	r.EraseAt(7, 4)
+	assert.Equal(t, "你好 Hellorld", r.String())
+	assert.Equal(t, 7, left.Pos())
+	assert.Equal(t, 7, right.Pos())
+	assert.Equal(t, 8, after.Pos())
+
+	// byte addressed edits
+	r.InsertAtByte(0, []byte("!"))
+	assert.Equal(t, 8, left.Pos())
+	r.EraseBytes(0, 4) // "!你"
+	assert.Equal(t, 6, left.Pos())
+
+	after.Move(0)
+	assert.Equal(t, 0, after.Pos())
+	after.Delete()
+	assert.Equal(t, -1, after.Pos())
+	r.Insert(0, "abc")
+	assert.Equal(t, 9, left.Pos())
+	assert.Equal(t, 9, right.Pos())
+}
+
+func TestMark_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1337))
+	r := New()
+	r.Insert(0, a)
+	runes := r.Runes()
+
+	type model struct {
+		m   *Mark
+		pos int
+	}
+	var marks []model
+	for i := 0; i < 2000; i++ {
+		pos := rnd.Intn(runes + 1)
+		marks = append(marks, model{r.NewMark(pos, Gravity(rnd.Intn(2))), pos})
+	}
+
+	for i := 0; i < 500; i++ {
+		at := rnd.Intn(runes + 1)
+		if rnd.Intn(2) == 0 {
+			n := rnd.Intn(100)
+			r.EraseAt(at, n)
+			n = min(n, runes-at)
+			runes -= n
+			for j := range marks {
+				switch {
+				case marks[j].pos > at+n:
+					marks[j].pos -= n
+				case marks[j].pos > at:
+					marks[j].pos = at
+				}
+			}
+		} else {
+			n := rnd.Intn(100)
+			r.InsertRunes(at, make([]rune, n))
+			runes += n
+			for j := range marks {
+				if marks[j].pos > at || (marks[j].pos == at && marks[j].m.Gravity() == RightGravity) {
+					marks[j].pos += n
+				}
+			}
+		}
+		if i%50 == 0 {
+			j := rnd.Intn(len(marks))
+			marks[j].m.Delete()
+			marks = append(marks[:j], marks[j+1:]...)
+		}
+	}
+	for _, m := range marks {
+		assert.Equal(t, m.pos, m.m.Pos())
+	}
+}
@@ -0,0 +1,65 @@
+package skiprope
+
+import (
+	"math/rand"
+	"unicode/utf8"
+)
+
+// Options configures a rope. The zero value configures a rope just like New does.
+type Options struct {
+	// Bias is the bias of the rope, which is used instead of the package-level Bias if it is not 0.
+	Bias int
+
+	// Seed seeds the rope's own source of randomness, if it is not 0 and Rand is nil.
+	Seed int64
+
+	// Rand is the rope's own source of randomness, which decides the heights of new knots. If neither Rand nor Seed is set,
+	// the rope uses the source of randomness shared by all ropes.
+	Rand rand.Source
+
+	// BucketSize is the number of bytes a knot holds, which is used instead of BucketSize if it is not 0.
+	// Smaller buckets make edits cheaper, while larger buckets make the rope smaller and faster to scan.
+	// It cannot be less than utf8.UTFMax, so that every rune fits in a knot.
+	BucketSize int
+}
+
+// NewWithOptions creates a new Rope with the given options.
+func NewWithOptions(opts Options) *Rope {
+	r := &Rope{
+		bias:       opts.Bias,
+		bucketSize: opts.BucketSize,
+	}
+	if r.bucketSize != 0 && r.bucketSize < utf8.UTFMax {
+		r.bucketSize = utf8.UTFMax
+	}
+	switch {
+	case opts.Rand != nil:
+		r.rand = rand.New(opts.Rand)
+	case opts.Seed != 0:
+		r.rand = rand.New(rand.NewSource(opts.Seed))
+	}
+	Init(r)
+	return r
+}
+
+// heightBias returns the bias of the rope.
+func (r *Rope) heightBias() int {
+	if r.bias != 0 {
+		return r.bias
+	}
+	return Bias
+}
+
+// newSibling creates a new empty rope with the same options as the rope. If the rope has its own source of randomness,
+// the new rope gets its own source too, which is seeded from the rope's.
+func (r *Rope) newSibling() *Rope {
+	retVal := &Rope{
+		bias:       r.bias,
+		bucketSize: r.bucketSize,
+	}
+	if r.rand != nil {
+		retVal.rand = rand.New(rand.NewSource(r.rand.Int63()))
+	}
+	Init(retVal)
+	return retVal
+}
@@ -0,0 +1,111 @@
+package skiprope
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// heights returns the heights of the knots of the rope, which describe the shape of the skiplist.
+func heights(r *Rope) (retVal []int) {
+	for k := &r.Head; k != nil; k = k.nexts[0].knot {
+		retVal = append(retVal, k.height)
+	}
+	return retVal
+}
+
+func TestNewWithOptions(t *testing.T) {
+	const alphabet = "ab\n你好 😀"
+	ar := []rune(alphabet)
+
+	for _, size := range []int{1, 4, 16, 64, 200} {
+		rnd := rand.New(rand.NewSource(1337))
+		r := NewWithOptions(Options{BucketSize: size, Seed: 1337})
+		var model []rune
+		for i := 0; i < 500; i++ {
+			point := rnd.Intn(len(model) + 1)
+			if rnd.Intn(3) == 0 && len(model) > 0 {
+				n := min(rnd.Intn(20), len(model)-point)
+				if err := r.EraseAt(point, n); err != nil {
+					t.Fatal(err)
+				}
+				model = append(model[:point], model[point+n:]...)
+				continue
+			}
+			ins := make([]rune, rnd.Intn(30))
+			for j := range ins {
+				ins[j] = ar[rnd.Intn(len(ar))]
+			}
+			if err := r.InsertRunes(point, ins); err != nil {
+				t.Fatal(err)
+			}
+			model = append(model[:point], append(ins, model[point:]...)...)
+		}
+		assert.Equal(t, string(model), r.String(), "BucketSize %d", size)
+		validRope(t, r)
+
+		for k := &r.Head; k != nil; k = k.nexts[0].knot {
+			assert.Equal(t, max(size, 4), len(k.data), "BucketSize %d", size)
+		}
+
+		// snapshots and splits keep the options
+		snap := r.Snapshot()
+		_, right := r.Split(r.Runes() / 2)
+		assert.Equal(t, max(size, 4), len(snap.Head.data))
+		assert.Equal(t, max(size, 4), len(right.Head.data))
+		r.Concat(right)
+		assert.Equal(t, string(model), r.String())
+		assert.Equal(t, string(model), snap.String())
+	}
+}
+
+func TestNewWithOptions_Mixed(t *testing.T) {
+	// knots of different sizes end up in the same rope when ropes with different options are concatenated
+	str := strings.Repeat("Hello World! 你好世界!\n", 20)
+	small := NewWithOptions(Options{BucketSize: 8})
+	small.Insert(0, str)
+	large := NewWithOptions(Options{BucketSize: 256})
+	large.Insert(0, str)
+
+	small.Concat(large)
+	validRope(t, small)
+	assert.Equal(t, str+str, small.String())
+
+	left, right := small.Split(len([]rune(str)) + 3)
+	validRope(t, left)
+	validRope(t, right)
+	assert.Equal(t, str[3:], right.String())
+
+	right.Insert(10, "More text in a knot that is larger than the buckets of the rope")
+	right.EraseAt(0, 20)
+	validRope(t, right)
+}
+
+func TestNewWithOptions_Seed(t *testing.T) {
+	assert := assert.New(t)
+	edit := func(r *Rope) {
+		for i := 0; i < 200; i++ {
+			r.Insert(r.Runes()/2, "Hello World! 你好世界!\n")
+		}
+	}
+
+	a := NewWithOptions(Options{Seed: 1337})
+	b := NewWithOptions(Options{Rand: rand.NewSource(1337)})
+	edit(a)
+	edit(b)
+	assert.Equal(heights(a), heights(b))
+
+	// a higher bias makes taller knots
+	c := NewWithOptions(Options{Seed: 1337, Bias: 80})
+	edit(c)
+	sum := func(hs []int) (retVal int) {
+		for _, h := range hs {
+			retVal += h
+		}
+		return retVal
+	}
+	assert.True(sum(heights(c)) > sum(heights(a)))
+	assert.Equal(a.String(), c.String())
+}
@@ -0,0 +1,197 @@
+package skiprope
+
+import (
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+)
+
+// FindRegexp returns the location of the first match of the regular expression at or after the given point, or nil if there is none.
+// Like regexp.Regexp.FindSubmatchIndex, the location holds the start and end points of the match, followed by those of each submatch.
+// Submatches which are not part of the match have points of -1.
+//
+// The matching starts at the point as if it were the start of the text, so `^` and `\b` match at the point.
+func (r *Rope) FindRegexp(re *regexp.Regexp, from int) []int {
+	loc := r.findRegexp(re, from)
+	if loc == nil {
+		return nil
+	}
+	return r.runeLoc(loc)
+}
+
+// FindAllRegexp returns the locations of the successive non-overlapping matches of the regular expression, starting from the given point.
+// If n >= 0, at most n matches are returned. Empty matches abutting a preceding match are ignored.
+//
+// As with FindRegexp, the given point is treated as the start of the text, but the matches after the first are found
+// as regexp.Regexp.FindAllIndex would find them, so `^` does not match at the end of the previous match.
+func (r *Rope) FindAllRegexp(re *regexp.Regexp, from, n int) (retVal [][]int) {
+	for _, loc := range r.findAllRegexp(re, from, n) {
+		retVal = append(retVal, r.runeLoc(loc))
+	}
+	return retVal
+}
+
+// ReplaceAllRegexp replaces all the matches of the regular expression with the template, in which `$1` or `${name}`
+// are replaced by the text of the submatch, as with regexp.Regexp.Expand. It returns the number of replacements.
+//
+// The replacements are applied with ApplyEdits, so they are undone in one step.
+func (r *Rope) ReplaceAllRegexp(re *regexp.Regexp, template string) (int, error) {
+	locs := r.findAllRegexp(re, 0, -1)
+	edits := make([]Edit, 0, len(locs))
+	for _, loc := range locs {
+		// the submatches are within the match, so only the match is needed for the expansion
+		src := r.SubstrByteRange(loc[0], loc[1])
+		match := make([]int, len(loc))
+		for i, l := range loc {
+			match[i] = -1
+			if l >= 0 {
+				match[i] = l - loc[0]
+			}
+		}
+		point := r.RuneOffset(loc[0])
+		edits = append(edits, Edit{
+			Point: point,
+			N:     r.RuneOffset(loc[1]) - point,
+			Data:  re.Expand(nil, []byte(template), src, match),
+		})
+	}
+	if err := r.ApplyEdits(edits); err != nil {
+		return 0, err
+	}
+	return len(edits), nil
+}
+
+// findRegexp returns the location of the first match of the regular expression at or after the point, in byte offsets.
+func (r *Rope) findRegexp(re *regexp.Regexp, from int) []int {
+	return findScanner(re, NewScannerAt(r, from))
+}
+
+// findScanner returns the location of the first match of the regular expression at or after the scanner, in byte offsets.
+func findScanner(re *regexp.Regexp, s *Scanner) []int {
+	start := s.readBytes
+	loc := re.FindReaderSubmatchIndex(s)
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += start
+		}
+	}
+	return loc
+}
+
+// findAllRegexp is like FindAllRegexp, except that the locations are in byte offsets.
+//
+// The search resumes at the byte offset of the end of each match rather than at its point, as the end of a match may be
+// in the middle of an ill-formed UTF-8 sequence, which has no point of its own.
+//
+// Only the first search treats its start as the start of the text. The searches after it are given the rune before the point at
+// which they resume, so that `^`, `\A` and `\b` match as they would had the search continued.
+func (r *Rope) findAllRegexp(re *regexp.Regexp, from, n int) (retVal [][]int) {
+	s := NewScannerAt(r, from)
+	resumed := resumeRegexp(re)
+	prevEnd := -1
+	for n < 0 || len(retVal) < n {
+		var loc []int
+		if prevEnd < 0 || resumed == nil {
+			loc = findScanner(re, s)
+		} else {
+			loc = r.findResumed(resumed, s)
+		}
+		if loc == nil {
+			break
+		}
+		if loc[0] != loc[1] || loc[0] != prevEnd {
+			retVal = append(retVal, loc)
+		}
+		prevEnd = loc[1]
+
+		if _, err := s.Seek(int64(loc[1]), io.SeekStart); err != nil {
+			break
+		}
+		if loc[0] == loc[1] {
+			// move past the rune after the empty match, as the regexp package does
+			if _, _, err := s.ReadRune(); err != nil {
+				break
+			}
+		}
+	}
+	return retVal
+}
+
+// resumeRegexp returns a regular expression which matches the same as re, but skips the first rune of the text and reports
+// the match of re as its first submatch. It returns nil if re has no empty-width assertions that look at the rune before a point,
+// in which case a search can be resumed without it.
+func resumeRegexp(re *regexp.Regexp) *regexp.Regexp {
+	if prog, err := syntax.Parse(re.String(), syntax.Perl); err == nil && !looksBehind(prog) {
+		return nil
+	}
+	return regexp.MustCompile(`\A(?s:.)(?s:.*?)(` + re.String() + `)`)
+}
+
+// looksBehind checks if the regular expression has an empty-width assertion which looks at the rune before a point.
+func looksBehind(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpBeginText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	}
+	for _, sub := range re.Sub {
+		if looksBehind(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// findResumed returns the location of the first match at or after the scanner of a regular expression made by resumeRegexp,
+// in byte offsets. The scanner has to be past the start of the rope.
+func (r *Rope) findResumed(resumed *regexp.Regexp, s *Scanner) []int {
+	start := s.readBytes
+	var prev [1]byte
+	if _, err := r.ReadAt(prev[:], int64(start-1)); err != nil {
+		return nil
+	}
+	loc := resumed.FindReaderSubmatchIndex(&lookbehindReader{prev: prev[0], s: s})
+	if loc == nil {
+		return nil
+	}
+	loc = loc[2:]
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += start - 1
+		}
+	}
+	return loc
+}
+
+// lookbehindReader is an io.RuneReader which reads the byte before the scanner as a rune of its own, and then reads from the scanner.
+//
+// Only ASCII runes are newlines or word characters, so a byte which is not ASCII is read as utf8.RuneError. The assertions see
+// any other rune the same way, and the byte is never decoded together with the bytes after it.
+type lookbehindReader struct {
+	prev byte
+	read bool
+	s    *Scanner
+}
+
+func (l *lookbehindReader) ReadRune() (rune, int, error) {
+	if l.read {
+		return l.s.ReadRune()
+	}
+	l.read = true
+	if l.prev >= utf8.RuneSelf {
+		return utf8.RuneError, 1, nil
+	}
+	return rune(l.prev), 1, nil
+}
+
+// runeLoc converts a location in byte offsets to points.
+func (r *Rope) runeLoc(loc []int) []int {
+	retVal := make([]int, len(loc))
+	for i, l := range loc {
+		retVal[i] = -1
+		if l >= 0 {
+			retVal[i] = r.RuneOffset(l)
+		}
+	}
+	return retVal
+}
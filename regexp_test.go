@@ -0,0 +1,139 @@
+package skiprope
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var regexpText = strings.Repeat("Hello World! 你好世界! mail alice@example today, or bob@example.\n", 20)
+
+func TestRope_FindRegexp(t *testing.T) {
+	assert := assert.New(t)
+	r := NewFromBytes([]byte(regexpText))
+
+	toRunes := func(s string, loc []int) []int {
+		if loc == nil {
+			return nil
+		}
+		retVal := make([]int, len(loc))
+		for i, l := range loc {
+			retVal[i] = -1
+			if l >= 0 {
+				retVal[i] = utf8.RuneCountInString(s[:l])
+			}
+		}
+		return retVal
+	}
+
+	for _, pattern := range []string{`世界`, `(\w+)@(\w+)`, `o(x)?d`, `\n`, `missing`} {
+		re := regexp.MustCompile(pattern)
+		for _, from := range []int{0, 1, 17, 100, 1000} {
+			s := string([]rune(regexpText)[from:])
+			expected := toRunes(s, re.FindStringSubmatchIndex(s))
+			for i := range expected {
+				if expected[i] >= 0 {
+					expected[i] += from
+				}
+			}
+			assert.Equal(expected, r.FindRegexp(re, from), "FindRegexp(%q, %d)", pattern, from)
+		}
+
+		var expected [][]int
+		for _, loc := range re.FindAllStringSubmatchIndex(regexpText, -1) {
+			expected = append(expected, toRunes(regexpText, loc))
+		}
+		assert.Equal(expected, r.FindAllRegexp(re, 0, -1), "FindAllRegexp(%q)", pattern)
+		if len(expected) > 3 {
+			assert.Equal(expected[:3], r.FindAllRegexp(re, 0, 3), "FindAllRegexp(%q)", pattern)
+		}
+	}
+
+	// empty matches
+	r = New()
+	r.Insert(0, "abaab世")
+	re := regexp.MustCompile(`a*`)
+	var expected [][]int
+	for _, loc := range re.FindAllStringIndex("abaab世", -1) {
+		expected = append(expected, toRunes("abaab世", loc))
+	}
+	assert.Equal(expected, r.FindAllRegexp(re, 0, -1))
+}
+
+func TestRope_Regexp_InvalidUTF8(t *testing.T) {
+	assert := assert.New(t)
+	latin1 := "\xa3" + strings.Repeat("5 \xa9 2020, 30\xb0 ", 10)
+	for _, str := range []string{"a\xe4\xb8", "\xff\xfe\x80abc", latin1} {
+		for _, pattern := range []string{`x*`, `.`, `\d+`} {
+			re := regexp.MustCompile(pattern)
+			r := New()
+			if err := r.InsertBytes(0, []byte(str)); err != nil {
+				t.Fatal(err)
+			}
+
+			var expected [][]int
+			for _, loc := range re.FindAllStringIndex(str, -1) {
+				expected = append(expected, []int{utf8.RuneCountInString(str[:loc[0]]), utf8.RuneCountInString(str[:loc[1]])})
+			}
+			assert.Equal(expected, r.FindAllRegexp(re, 0, -1), "FindAllRegexp(%q) on %q", pattern, str)
+
+			if _, err := r.ReplaceAllRegexp(re, "<$0>"); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(re.ReplaceAllString(str, "<$0>"), r.String(), "ReplaceAllRegexp(%q) on %q", pattern, str)
+		}
+	}
+}
+
+func TestRope_Regexp_Assertions(t *testing.T) {
+	assert := assert.New(t)
+	cases := []struct{ pattern, str string }{
+		{`^a`, "aaa"},
+		{`(?m)^x`, "xx\nx"},
+		{`a|\bb`, "ab"},
+		{`\Bb|a`, "ab b"},
+		{`\Aa|b`, "aab"},
+		{`(?m)^$`, "\n\na\n"},
+		{`\b`, "héllo, 世界 wörld"},
+	}
+	for _, c := range cases {
+		re := regexp.MustCompile(c.pattern)
+		r := NewFromBytes([]byte(c.str))
+
+		var expected [][]int
+		for _, loc := range re.FindAllStringIndex(c.str, -1) {
+			expected = append(expected, []int{utf8.RuneCountInString(c.str[:loc[0]]), utf8.RuneCountInString(c.str[:loc[1]])})
+		}
+		assert.Equal(expected, r.FindAllRegexp(re, 0, -1), "FindAllRegexp(%q) on %q", c.pattern, c.str)
+
+		if _, err := r.ReplaceAllRegexp(re, "<$0>"); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(re.ReplaceAllString(c.str, "<$0>"), r.String(), "ReplaceAllRegexp(%q) on %q", c.pattern, c.str)
+	}
+}
+
+func TestRope_ReplaceAllRegexp(t *testing.T) {
+	assert := assert.New(t)
+	r := NewFromBytes([]byte(regexpText))
+	h := NewHistory(r)
+
+	re := regexp.MustCompile(`(\w+)@(\w+)`)
+	n, err := r.ReplaceAllRegexp(re, "$2 at ${1}")
+	assert.Nil(err)
+	assert.Equal(40, n)
+	assert.Equal(re.ReplaceAllString(regexpText, "$2 at ${1}"), r.String())
+	validRope(t, r)
+
+	if err := h.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(regexpText, r.String())
+
+	n, err = r.ReplaceAllRegexp(regexp.MustCompile(`missing`), "")
+	assert.Nil(err)
+	assert.Equal(0, n)
+}
@@ -1,13 +1,37 @@
 // package skiprope provides rope-like data structure for efficient manipulation of large strings.
+//
+// Positions in a rope are points - the indices of runes - unless the name of the method says otherwise (bytes, lines, UTF-16).
+// Positions given by the user never cause a panic. Positions which are out of bounds are handled in one of three ways:
+//   - Methods which modify the rope clamp them to the bounds of the rope, as do methods which take a range or a point
+//     to start from, such as Substr, SubstrByteRange, IndexOf, FindFunc and NewScannerAt.
+//   - Methods which have no sensible result for a clamped position return ErrOutOfRange, which can be checked for with errors.Is.
+//     These are Before, After, Scanner.SeekRune and ReadAt, as well as ApplyEdits, which applies none of the edits if any of them
+//     is out of bounds. SubstrE, IndexE and ByteOffsetE are the checked versions of Substr, Index and ByteOffset.
+//   - The remaining methods, which return a single position or rune, such as Index, ByteOffset, RuneOffset, LineStart,
+//     PointFromUTF16 and NextWordBoundary, return -1.
 package skiprope
 
 import (
+	"errors"
+	"io"
+	"math/rand"
 	"unicode/utf8"
 )
 
+var (
+	// ErrNotFound is returned when no rune matches.
+	ErrNotFound = errors.New("Not found")
+
+	// ErrOutOfRange is returned when a point, offset or line is not in the rope.
+	ErrOutOfRange = errors.New("Index out of bounds")
+
+	// ErrInvalidUTF8 is returned when the bytes at a point are not a valid UTF-8 sequence.
+	ErrInvalidUTF8 = errors.New("Invalid UTF-8")
+)
+
 const (
 	MaxHeight  = 60 // maximum size of the skiplist
-	BucketSize = 64 // data bucket size in a knot - about 64 bytes is optimal for insertion on a core i7.
+	BucketSize = 64 // default data bucket size in a knot - about 64 bytes is optimal for insertion on a core i7.
 )
 
 // Bias indicates the probability that a new knot will have height of n+1.
@@ -16,6 +40,8 @@ const (
 //
 // The higher the bias is, the better the data structure is at performing end-of-string appends. The tradeoff is
 // performance of random writes will deterioriate.
+//
+// Bias is shared by all ropes. Use NewWithOptions to give a rope a bias of its own.
 var Bias = 20
 
 // Rope is a rope data structure built on top of a skip list.
@@ -23,27 +49,69 @@ type Rope struct {
 	Head  knot
 	size  int // number of bytes
 	runes int // number of code points
+	lines int // number of newlines
+
+	codeUnits int // number of UTF-16 code units
+
+	epoch  uint64 // knots with a different epoch are shared with other ropes, and have to be cloned before being modified
+	shared bool   // indicates that there may be knots that are shared with other ropes
+
+	history *History  // if not nil, edits are recorded in the history
+	marks   *markList // marks that move with the edits
+
+	observers []observer // functions that are called after every edit
+	observed  int        // number of observers ever added. Used to identify observers
+
+	rand       *rand.Rand // if not nil, the heights of new knots are drawn from it instead of the source shared by all ropes
+	bias       int        // if not 0, it is used instead of Bias
+	bucketSize int        // the size of the data bucket of new knots
 }
 
 // knot is a node in a rope.... because... geddit?
 type knot struct {
-	data   [BucketSize]byte // array is preallocated - 64*4 bytes used
-	nexts  []skipknot       // next
-	height int              // number of elements located in nexts. Minium height is 1
-	used   int              // indicates how many byte are used in data
+	data   []byte     // bucket is preallocated - its length is the capacity of the knot
+	nexts  []skipknot // next
+	height int        // number of elements located in nexts. Minium height is 1
+	used   int        // indicates how many byte are used in data
+	epoch  uint64     // the epoch of the rope which the knot belongs to
 }
 
-func newKnot(height int) *knot {
-	return &knot{
-		height: height,
-		nexts:  make([]skipknot, height),
+// knotBucket is a knot along with a bucket of the default size, so that both are allocated at once.
+type knotBucket struct {
+	knot
+	bucket [BucketSize]byte
+}
+
+func newKnot(height int, epoch uint64, size int) *knot {
+	var k *knot
+	if size == BucketSize {
+		kb := new(knotBucket)
+		k = &kb.knot
+		k.data = kb.bucket[:]
+	} else {
+		k = &knot{data: make([]byte, size)}
 	}
+	k.height = height
+	k.nexts = make([]skipknot, height)
+	k.epoch = epoch
+	return k
+}
+
+// clone creates a copy of the knot which belongs to the given epoch.
+func (k *knot) clone(epoch uint64) *knot {
+	retVal := newKnot(k.height, epoch, len(k.data))
+	copy(retVal.data, k.data[:k.used])
+	copy(retVal.nexts, k.nexts)
+	retVal.used = k.used
+	return retVal
 }
 
 type skipknot struct {
 	*knot
 	skipped      int // number of bytes between the start and current node and the start of the next
 	skippedRunes int // number of runes between the start and current node and the start of the next
+	skippedLines int // number of newlines between the start and current node and the start of the next
+	skippedUTF16 int // number of UTF-16 code units between the start and current node and the start of the next
 }
 
 // New creates a new Rope.
@@ -53,12 +121,23 @@ func New() *Rope {
 	return &r
 }
 
-// Init re-initializes the rope
+// Init re-initializes the rope. The options the rope was created with are kept.
 func Init(r *Rope) {
+	if r.bucketSize == 0 {
+		r.bucketSize = BucketSize
+	}
+	r.epoch = nextEpoch()
 	r.Head = knot{
+		data:   make([]byte, r.bucketSize),
 		height: 1,
 		nexts:  make([]skipknot, MaxHeight),
+		epoch:  r.epoch,
 	}
+	r.size = 0
+	r.runes = 0
+	r.lines = 0
+	r.codeUnits = 0
+	r.shared = false
 }
 
 // Size is the length of the rope.
@@ -67,7 +146,7 @@ func (r *Rope) Size() int { return r.size }
 // Runes is the number of runes in the rope
 func (r *Rope) Runes() int { return r.runes }
 
-// SubstrRunes is like Substr, but returns []rune
+// SubstrRunes is like Substr, but returns []rune.
 func (r *Rope) SubstrRunes(pointA, pointB int) []rune {
 	return []rune(string(r.SubstrBytes(pointA, pointB)))
 }
@@ -77,25 +156,38 @@ func (r *Rope) SubstrRunes(pointA, pointB int) []rune {
 //
 // Example: "你好world" has a length of 11 bytes. If we only want "好",
 // we'd have to call SubstrBytes(1, 2), not SubstrBytes(3, 6) which you would if you
-// were dealing with pure bytes
+// were dealing with pure bytes.
+//
+// The points may be given in either order, and they are clamped to the bounds of the rope. Use SubstrE to have them checked instead.
 func (r *Rope) SubstrBytes(pointA, pointB int) []byte {
-	lastPos := r.runes
-	a := clamp(min(pointA, pointB), 0, lastPos)
-	b := clamp(max(pointB, pointB), 0, lastPos)
+	retVal, _ := r.substr(clamp(pointA, 0, r.runes), clamp(pointB, 0, r.runes))
+	return retVal
+}
 
-	size := b - a
-	if size == 0 {
-		return nil
+// SubstrE is like Substr, except that the points are not clamped. ErrOutOfRange is returned if either point is not in the rope.
+func (r *Rope) SubstrE(pointA, pointB int) (string, error) {
+	retVal, err := r.substr(pointA, pointB)
+	return string(retVal), err
+}
+
+// substr returns the bytes between the points, which may be given in either order.
+func (r *Rope) substr(pointA, pointB int) ([]byte, error) {
+	a, b := min(pointA, pointB), max(pointA, pointB)
+	if a < 0 || b > r.runes {
+		return nil, ErrOutOfRange
+	}
+	if a == b {
+		return nil, nil
 	}
 	s := skiplist{r: r}
 	var k1, k2 *knot
 	var start, end, retOffset, startSkipped, endSkipped int
 	var err error
 	if k1, start, startSkipped, err = s.find(a); err != nil {
-		panic(err)
+		return nil, err
 	}
 	if k2, end, endSkipped, err = s.find(b); err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	retVal := make([]byte, 0, (endSkipped+end)-(start+startSkipped))
@@ -117,10 +209,10 @@ func (r *Rope) SubstrBytes(pointA, pointB int) []byte {
 			break
 		}
 	}
-	return retVal
+	return retVal, nil
 }
 
-// Substr creates a substring.
+// Substr creates a substring. The points are handled as they are by SubstrBytes.
 func (r *Rope) Substr(pointA, pointB int) string {
 	return string(r.SubstrBytes(pointA, pointB))
 }
@@ -130,19 +222,23 @@ func (r *Rope) InsertRunes(point int, data []rune) (err error) {
 	return r.InsertBytes(point, []byte(string(data)))
 }
 
-// InsertBytes inserts the bytes at the point.
+// InsertBytes inserts the bytes at the point. The point is clamped to the bounds of the rope.
 func (r *Rope) InsertBytes(point int, data []byte) (err error) {
-	if point > r.runes {
-		point = r.runes
-	}
+	point = clamp(point, 0, r.runes)
 
 	// search for the Knot where we'll insert
 	var k *knot
 	s := skiplist{r: r}
+	s.own(point)
 	if k, err = s.find2(point); err != nil {
 		return err
 	}
-	return s.insert(k, data)
+	runes := r.runes
+	if err = s.insert(k, data); err != nil {
+		return err
+	}
+	r.afterInsert(point, r.runes-runes, data)
+	return nil
 }
 
 // Insert inserts the string at the point
@@ -150,53 +246,230 @@ func (r *Rope) Insert(at int, str string) error {
 	return r.InsertBytes(at, []byte(str))
 }
 
-// EraseAt erases n runes starting from the point.
+// EraseAt erases n runes starting from the point. The point is clamped to the bounds of the rope, and n to the runes after the point.
 func (r *Rope) EraseAt(point, n int) (err error) {
-	if point > r.runes {
-		point = r.runes
-	}
-	if n >= r.runes-point {
-		n = r.runes - point
-	}
+	point = clamp(point, 0, r.runes)
+	n = clamp(n, 0, r.runes-point)
 	var k *knot
 	s := skiplist{r: r}
+	s.own(point + n)
 	if k, err = s.find2(point); err != nil {
 		return err
 	}
+	c := r.beforeErase(point, n)
 	s.del(k, n)
+	r.afterErase(c)
 	return nil
 }
 
-// Index returns the rune at the given index.
+// Replace replaces n runes starting from the point with the bytes. The replacement is a single edit: observers are notified once,
+// and it is undone and redone in one step. The point and n are clamped as they are by EraseAt.
+func (r *Rope) Replace(point, n int, data []byte) (err error) {
+	point = clamp(point, 0, r.runes)
+	n = clamp(n, 0, r.runes-point)
+	var k *knot
+	var offset int
+	s := skiplist{r: r}
+	s.own(point + n)
+	if k, offset, _, err = s.find(point); err != nil {
+		return err
+	}
+	erased, c := r.beforeReplace(point, n)
+	runes := r.runes
+
+	// if the replaced runes are in the knot, and the knot has space for the new bytes, the bytes are overwritten in place.
+	var erasedBytes int
+	inKnot := n <= k.nexts[0].skippedRunes-s.s[0].skippedRunes
+	if inKnot {
+		erasedBytes = byteOffset(k.data[offset:k.used], n)
+	}
+	if inKnot && k.used-erasedBytes+len(data) <= len(k.data) {
+		old := k.data[offset : offset+erasedBytes]
+		byteCount := len(data) - erasedBytes
+		runeCount := utf8.RuneCount(data) - n
+		lineCount := countLines(data) - countLines(old)
+		unitCount := countUTF16(data) - countUTF16(old)
+
+		copy(k.data[offset+len(data):], k.data[offset+erasedBytes:k.used])
+		copy(k.data[offset:], data)
+		k.used += byteCount
+		s.updateOffsets(byteCount, runeCount, lineCount, unitCount)
+		r.size += byteCount
+		r.runes += runeCount
+		r.lines += lineCount
+		r.codeUnits += unitCount
+	} else {
+		// the search path is still valid after the deletion, as only the runes after the point are deleted.
+		s.del(k, n)
+		if err = s.insert(k, data); err != nil {
+			return err
+		}
+	}
+	r.afterReplace(point, r.runes-runes+n, erased, data, c)
+	if c != nil {
+		r.notify(*c)
+	}
+	return nil
+}
+
+// Index returns the rune at the given index. It returns -1 if the index is out of bounds, and 0 at the end of the rope.
+// Use IndexE to tell these apart from the runes.
 func (r *Rope) Index(at int) rune {
+	char, _, err := r.index(at)
+	switch {
+	case err == ErrOutOfRange:
+		return -1
+	case at == r.runes:
+		return 0
+	}
+	return char
+}
+
+// IndexE is like Index, except that an error is returned instead. ErrOutOfRange is returned if the index is not that of a rune,
+// including the index at the end of the rope, and ErrInvalidUTF8 is returned along with utf8.RuneError if the rune is not valid UTF-8.
+func (r *Rope) IndexE(at int) (rune, error) {
+	if at >= r.runes {
+		return -1, ErrOutOfRange
+	}
+	char, size, err := r.index(at)
+	if err != nil {
+		return -1, err
+	}
+	if char == utf8.RuneError && size == 1 {
+		return char, ErrInvalidUTF8
+	}
+	return char, nil
+}
+
+// index decodes the rune at the given index. At the end of the rope, utf8.RuneError is returned, with a size of 0.
+func (r *Rope) index(at int) (char rune, size int, err error) {
 	s := skiplist{r: r}
 	var k *knot
 	var offset int
-	var err error
-
 	if k, offset, _, err = s.find(at); err != nil {
-		return -1
+		return -1, 0, err
 	}
-	if offset == BucketSize {
-		char, _ := utf8.DecodeRune(k.nexts[0].data[0:])
-		return char
+	// the search stays in the knot before the point if the point is at the start of a knot
+	if offset == k.used {
+		if k = k.nexts[0].knot; k == nil {
+			return utf8.RuneError, 0, nil
+		}
+		offset = 0
 	}
-	char, _ := utf8.DecodeRune(k.data[offset:])
-	return char
+	char, size = utf8.DecodeRune(k.data[offset:k.used])
+	return char, size, nil
 }
 
-// ByteOffset returns the byte offset of a rune at the given point.
+// ByteOffset returns the byte offset of a rune at the given point. It returns -1 if the point is out of bounds.
+// Use ByteOffsetE to have an error returned instead.
 func (r *Rope) ByteOffset(at int) int {
+	retVal, _ := r.ByteOffsetE(at)
+	return retVal
+}
+
+// ByteOffsetE is like ByteOffset, except that ErrOutOfRange is returned along with -1 if the point is out of bounds.
+func (r *Rope) ByteOffsetE(at int) (int, error) {
 	s := skiplist{r: r}
 	// var k *knot
 	var offset, skippedBytes int
 	var err error
 
 	if _, offset, skippedBytes, err = s.find(at); err != nil {
+		return -1, err
+	}
+
+	return offset + skippedBytes, nil
+}
+
+// RuneOffset returns the point of the rune at the given byte offset. It is the inverse of ByteOffset.
+// If the byte offset falls in the middle of a UTF-8 sequence, the point of the rune is returned.
+func (r *Rope) RuneOffset(at int) int {
+	if at < 0 {
+		return -1
+	}
+	s := skiplist{r: r}
+	var offsetRunes, skippedRunes int
+	var err error
+
+	if _, offsetRunes, _, skippedRunes, err = s.findByte(at); err != nil {
 		return -1
 	}
+	return offsetRunes + skippedRunes
+}
+
+// InsertAtByte inserts the bytes at the given byte offset.
+// If the byte offset falls in the middle of a UTF-8 sequence, the bytes are inserted before the rune.
+func (r *Rope) InsertAtByte(at int, data []byte) (err error) {
+	at = clamp(at, 0, r.size)
+
+	var k *knot
+	s := skiplist{r: r}
+	if r.shared {
+		s.own(r.RuneOffset(at))
+	}
+	var offsetRunes, skippedRunes int
+	if k, offsetRunes, _, skippedRunes, err = s.findByte(at); err != nil {
+		return err
+	}
+	runes := r.runes
+	if err = s.insert(k, data); err != nil {
+		return err
+	}
+	r.afterInsert(offsetRunes+skippedRunes, r.runes-runes, data)
+	return nil
+}
+
+// EraseBytes erases n bytes starting from the given byte offset. Offsets that fall in the middle of a
+// UTF-8 sequence are moved to the start of the rune, so only whole runes are erased.
+func (r *Rope) EraseBytes(at, n int) (err error) {
+	at = clamp(at, 0, r.size)
+	end := clamp(at+n, at, r.size)
+
+	var k *knot
+	var offsetRunes, skippedRunes int
+	s := skiplist{r: r}
+	if _, offsetRunes, _, skippedRunes, err = s.findByte(end); err != nil {
+		return err
+	}
+	endRunes := offsetRunes + skippedRunes
+	s.own(endRunes)
+	if k, offsetRunes, _, skippedRunes, err = s.findByte(at); err != nil {
+		return err
+	}
+	point := offsetRunes + skippedRunes
+	c := r.beforeErase(point, endRunes-point)
+	s.del(k, endRunes-point)
+	r.afterErase(c)
+	return nil
+}
+
+// SubstrByteRange is like SubstrBytes, but both byteA and byteB are byte offsets.
+// Offsets that fall in the middle of a UTF-8 sequence are moved to the start of the rune.
+func (r *Rope) SubstrByteRange(byteA, byteB int) []byte {
+	a := clamp(min(byteA, byteB), 0, r.size)
+	b := clamp(max(byteA, byteB), 0, r.size)
+
+	s := skiplist{r: r}
+	k, _, start, _, err := s.findByte(b)
+	if err != nil {
+		return nil
+	}
+	b = s.s[r.Head.height-1].skipped
+	if k, _, start, _, err = s.findByte(a); err != nil {
+		return nil
+	}
+	a = s.s[r.Head.height-1].skipped
+	if a == b {
+		return nil
+	}
 
-	return offset + skippedBytes
+	retVal := make([]byte, 0, b-a)
+	for n := k; n != nil && len(retVal) < b-a; n = n.nexts[0].knot {
+		end := min(n.used, start+b-a-len(retVal))
+		retVal = append(retVal, n.data[start:end]...)
+		start = 0
+	}
+	return retVal
 }
 
 // String returns the rope as a full string.
@@ -204,66 +477,72 @@ func (r *Rope) String() string {
 	return r.Substr(0, r.runes)
 }
 
-// Before returns the index of the first rune that matches the function before the given point.
+// Before returns the point after the nearest rune before the given point which matches the function, along with the rune.
+// If the rune at the point matches, the point itself is returned. If no rune matches, ErrNotFound is returned.
 //
 // Example: "Hello World". Let's say `at` is at 9 (rune = r). And we want to find the whitespace before it.
-// This function will return 5, which is the byte index of the rune immediately after the whitespace.
+// This function will return 6, which is the index of the rune immediately after the whitespace.
 func (r *Rope) Before(at int, fn func(r rune) bool) (retVal int, retRune rune, err error) {
+	if at < 0 || at > r.runes {
+		return -1, -1, ErrOutOfRange
+	}
+	if at < r.runes {
+		if char := r.Index(at); fn(char) {
+			return at, char, nil
+		}
+	}
+
+	s := NewReverseScanner(r, at)
+	for point := at; point > 0; point-- {
+		char, _, err := s.ReadRune()
+		if err != nil {
+			return -1, -1, err
+		}
+		if fn(char) {
+			return point, char, nil
+		}
+	}
+	return -1, -1, ErrNotFound
+}
+
+// After returns the point of the nearest rune at or after the given point which matches the function, along with the rune.
+// If no rune matches, ErrNotFound is returned.
+//
+// Example: "Hello World". Let's say `at` is at 1 (rune = e). And we want to find the whitespace after it.
+// This function will return 5, which is the index of the whitespace.
+func (r *Rope) After(at int, fn func(r rune) bool) (retVal int, retRune rune, err error) {
+	if at < 0 || at > r.runes {
+		return -1, -1, ErrOutOfRange
+	}
 	s := skiplist{r: r}
-	var k, prev *knot
+	var k *knot
 	var offset int
-	var char rune
-
 	if k, offset, _, err = s.find(at); err != nil {
 		return -1, -1, err
 	}
 
-	char, _ = utf8.DecodeRune(k.data[offset:])
-	if fn(char) {
-		return at, char, nil
-	}
-
-	// if it's within this block, then return immediately, otherwise, get previous block
-	var befores, start int
-	size := 1
-	for end := len(k.data[:offset]); end > start; end -= size {
-		char, size = utf8.DecodeLastRune(k.data[start:end])
-		if fn(char) {
-			return at - befores, char, nil
-		}
-		befores += size
-	}
-
-	// otherwise we'd have to iterate thru the blocks
-	befores = offset + 1
-	for {
-		for it := &r.Head; it != nil; it = it.nexts[0].knot {
-			if it == k {
-				// if prev == nil {
-				// 	prev = it
-				// }
-				break
-			}
-			prev = it
-		}
-		start = 0
-		size = 1
-		for end := len(prev.data) - 1; end > start; end -= size {
-			char, size = utf8.DecodeLastRune(prev.data[start:end])
+	retVal = at
+	for ; k != nil; k, offset = k.nexts[0].knot, 0 {
+		for offset < k.used {
+			char, size := utf8.DecodeRune(k.data[offset:k.used])
 			if fn(char) {
-				return at - befores, char, nil
+				return retVal, char, nil
 			}
-			befores += size
+			offset += size
+			retVal++
 		}
-		k = prev
-		// if k == &r.Head {
-		// 	break
-		// }
 	}
-	return
+	return -1, -1, ErrNotFound
 }
 
-// Write implements the io.Writer interface for a Rope. Existing contents of the Rope will be erased.
+// FindFunc returns the point of the first rune at or after the given point which matches the function.
+// Unlike After, the point is clamped to the bounds of the rope. If no rune matches, ErrNotFound is returned.
+func (r *Rope) FindFunc(from int, fn func(r rune) bool) (int, error) {
+	retVal, _, err := r.After(clamp(from, 0, r.runes), fn)
+	return retVal, err
+}
+
+// Write implements the io.Writer interface for a Rope. The bytes are appended to the end of the Rope.
 func (r *Rope) Write(p []byte) (int, error) {
 	err := r.Insert(r.runes, string(p))
 	if err != nil {
@@ -272,3 +551,115 @@ func (r *Rope) Write(p []byte) (int, error) {
 
 	return len(p), nil
 }
+
+// ReadAt implements io.ReaderAt. The offset is in bytes. As ReadAt does not modify the rope, it may be called concurrently.
+func (r *Rope) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, ErrOutOfRange
+	}
+	s := &Scanner{Rope: r}
+	if _, err = s.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if n, _ = s.Read(p); n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteTo implements io.WriterTo. The data of each knot is written with a separate call to w.Write, so w should be
+// buffered if writes are expensive.
+func (r *Rope) WriteTo(w io.Writer) (n int64, err error) {
+	return NewScanner(r).WriteTo(w)
+}
+
+// afterInsert is called after the data, which has the given number of runes, has been inserted at the point.
+func (r *Rope) afterInsert(point, runes int, data []byte) {
+	if r.history != nil {
+		r.history.recordInsert(point, data)
+	}
+	if r.marks != nil {
+		r.marks.inserted(point, runes)
+	}
+	if len(r.observers) > 0 {
+		c := Change{
+			Point:    point,
+			Offset:   r.ByteOffset(point),
+			Inserted: data,
+		}
+		c.Start.Line, c.Start.Col = r.PointToLineCol(point)
+		c.NewEnd.Line, c.NewEnd.Col = r.PointToLineCol(point + runes)
+		c.OldEnd = c.Start
+		r.notify(c)
+	}
+}
+
+// beforeErase is called before n runes are erased from the point. If the rope is observed,
+// it returns the Change describing the erasure, which is to be passed to afterErase.
+func (r *Rope) beforeErase(point, n int) (c *Change) {
+	if n <= 0 {
+		return nil
+	}
+	if r.history != nil {
+		r.history.recordErase(point, r.SubstrBytes(point, point+n))
+	}
+	if r.marks != nil {
+		r.marks.erased(point, n)
+	}
+	if len(r.observers) > 0 {
+		c = &Change{
+			Point:  point,
+			Offset: r.ByteOffset(point),
+			Erased: n,
+		}
+		c.ErasedBytes = r.ByteOffset(point+n) - c.Offset
+		c.Start.Line, c.Start.Col = r.PointToLineCol(point)
+		c.OldEnd.Line, c.OldEnd.Col = r.PointToLineCol(point + n)
+		c.NewEnd = c.Start
+	}
+	return c
+}
+
+// beforeReplace is called before n runes starting from the point are replaced. It returns the bytes to be erased if
+// they are needed by the history, and the Change describing the erasure if the rope is observed. Both are to be passed to afterReplace.
+func (r *Rope) beforeReplace(point, n int) (erased []byte, c *Change) {
+	if r.history != nil && n > 0 {
+		erased = r.SubstrBytes(point, point+n)
+	}
+	if r.marks != nil && n > 0 {
+		r.marks.erased(point, n)
+	}
+	if len(r.observers) > 0 {
+		c = &Change{
+			Point:  point,
+			Offset: r.ByteOffset(point),
+			Erased: n,
+		}
+		c.ErasedBytes = r.ByteOffset(point+n) - c.Offset
+		c.Start.Line, c.Start.Col = r.PointToLineCol(point)
+		c.OldEnd.Line, c.OldEnd.Col = r.PointToLineCol(point + n)
+	}
+	return erased, c
+}
+
+// afterReplace is called after the runes starting from the point are replaced by the data, which has the given number of runes.
+// It completes the Change returned by beforeReplace, but it is up to the caller to notify the observers.
+func (r *Rope) afterReplace(point, runes int, erased, data []byte, c *Change) {
+	if r.history != nil {
+		r.history.recordReplace(point, erased, data)
+	}
+	if r.marks != nil {
+		r.marks.inserted(point, runes)
+	}
+	if c != nil {
+		c.Inserted = data
+		c.NewEnd.Line, c.NewEnd.Col = r.PointToLineCol(point + runes)
+	}
+}
+
+// afterErase is called after the erasure described by c is done.
+func (r *Rope) afterErase(c *Change) {
+	if c != nil {
+		r.notify(*c)
+	}
+}
@@ -2,48 +2,75 @@ package skiprope
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
 	"testing"
 	"testing/quick"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func validRope(t *testing.T, r *Rope) {
 	assert := assert.New(t)
+	assert.NoError(r.Validate())
 	assert.Condition(func() bool { return r.Head.height >= 1 }, "Height has to be greater than 1")
 
 	last := r.Head.nexts[r.Head.height-1]
 	assert.Equal(r.size, last.skipped, "Expect to have skipped %d for the last element on the skiplist", r.size)
 	assert.Nil(last.knot, "Last Knot not nil")
 
-	var runeCount int
+	var total skipknot
 	s := skiplist{r: r}
 	for i := 0; i < r.Head.height; i++ {
 		s.s[i].knot = &r.Head
 	}
 
 	for n := &r.Head; n != nil; n = n.nexts[0].knot {
-		assert.Condition(func() bool { return n.used > 0 }, "Expected a used count of greater than 0")
+		assert.Condition(func() bool { return n.used > 0 || n == &r.Head }, "Expected a used count of greater than 0")
 		assert.Condition(func() bool { return n.height <= MaxHeight }, "node cannot be greater than MaxHeight - %d", n.height)
+		assert.Condition(func() bool { return n.height < r.Head.height || n == &r.Head }, "node cannot be as tall as the head - %d", n.height)
+
+		data := n.data[:n.used]
+		assert.Equal(n.used, n.nexts[0].skipped, "skipped bytes should be the same as the used bytes")
+		assert.Equal(utf8.RuneCount(data), n.nexts[0].skippedRunes, "skipped runes should be the same as the runes in the knot")
+		assert.Equal(countLines(data), n.nexts[0].skippedLines, "skipped lines should be the same as the lines in the knot")
+		assert.Equal(countUTF16(data), n.nexts[0].skippedUTF16, "skipped UTF-16 code units should be the same as the code units in the knot")
 
 		for i := 0; i < n.height; i++ {
 			assert.Equal(s.s[i].knot, n, "search[%d] should be %p", i, n)
-			assert.Equal(s.s[i].skipped, runeCount, "RuneCount should be the same as skipped.")
+			assert.Equal(total.skipped, s.s[i].skipped, "RuneCount should be the same as skipped.")
+			assert.Equal(total.skippedRunes, s.s[i].skippedRunes, "skippedRunes[%d]", i)
+			assert.Equal(total.skippedLines, s.s[i].skippedLines, "skippedLines[%d]", i)
+			assert.Equal(total.skippedUTF16, s.s[i].skippedUTF16, "skippedUTF16[%d]", i)
 
 			s.s[i].knot = n.nexts[i].knot
 			s.s[i].skipped += n.nexts[i].skipped
+			s.s[i].skippedRunes += n.nexts[i].skippedRunes
+			s.s[i].skippedLines += n.nexts[i].skippedLines
+			s.s[i].skippedUTF16 += n.nexts[i].skippedUTF16
 		}
-		runeCount += n.nexts[0].skipped
+		total.skipped += n.nexts[0].skipped
+		total.skippedRunes += n.nexts[0].skippedRunes
+		total.skippedLines += n.nexts[0].skippedLines
+		total.skippedUTF16 += n.nexts[0].skippedUTF16
 	}
 
 	for i := 0; i < r.Head.height; i++ {
 		assert.Nil(s.s[i].knot)
-		assert.Equal(runeCount, s.s[i].skipped)
+		assert.Equal(total.skipped, s.s[i].skipped)
+		assert.Equal(total.skippedRunes, s.s[i].skippedRunes)
 	}
 
-	assert.Equal(runeCount, r.size)
+	assert.Equal(total.skipped, r.size)
+	assert.Equal(total.skippedRunes, r.runes)
+	assert.Equal(total.skippedLines, r.lines)
+	assert.Equal(total.skippedUTF16, r.codeUnits)
 }
 
 func TestEmptyRope(t *testing.T) {
@@ -178,6 +205,124 @@ func TestBasic(t *testing.T) {
 	}
 }
 
+func TestRope_Index(t *testing.T) {
+	// knots which are not full, so that points at the start of a knot are found at the end of the knot before
+	str := strings.Repeat("Hello World! 你好世界!\n", 20)
+	r := NewFromBytes([]byte(str))
+	for i, char := range []rune(str) {
+		assert.Equal(t, char, r.Index(i), "Index(%d)", i)
+	}
+	assert.Equal(t, rune(0), r.Index(r.Runes()))
+}
+
+func TestRope_IndexE(t *testing.T) {
+	assert := assert.New(t)
+	r := New()
+	if err := r.InsertBytes(0, []byte("a你\xffb")); err != nil {
+		t.Fatal(err)
+	}
+
+	char, err := r.IndexE(1)
+	assert.Nil(err)
+	assert.Equal('你', char)
+
+	char, err = r.IndexE(2)
+	assert.True(errors.Is(err, ErrInvalidUTF8))
+	assert.Equal(utf8.RuneError, char)
+
+	for _, at := range []int{-1, 4, 100} {
+		char, err = r.IndexE(at)
+		assert.True(errors.Is(err, ErrOutOfRange), "IndexE(%d)", at)
+		assert.Equal(rune(-1), char)
+	}
+	assert.Equal(rune(-1), r.Index(-1))
+	assert.Equal(rune(0), r.Index(4))
+	assert.Equal(rune(-1), r.Index(100))
+}
+
+func TestRope_SubstrE(t *testing.T) {
+	assert := assert.New(t)
+	r := New()
+	if err := r.Insert(0, "你好world"); err != nil {
+		t.Fatal(err)
+	}
+
+	str, err := r.SubstrE(1, 4)
+	assert.Nil(err)
+	assert.Equal("好wo", str)
+
+	// either order
+	str, err = r.SubstrE(4, 1)
+	assert.Nil(err)
+	assert.Equal("好wo", str)
+	assert.Equal("好wo", r.Substr(4, 1))
+
+	str, err = r.SubstrE(0, 7)
+	assert.Nil(err)
+	assert.Equal("你好world", str)
+
+	for _, c := range [][2]int{{-1, 3}, {3, 8}, {-5, 100}} {
+		_, err = r.SubstrE(c[0], c[1])
+		assert.True(errors.Is(err, ErrOutOfRange), "SubstrE(%d, %d)", c[0], c[1])
+	}
+	// Substr clamps instead
+	assert.Equal("你好w", r.Substr(-1, 3))
+	assert.Equal("rld", r.Substr(4, 100))
+}
+
+func TestRope_Clamping(t *testing.T) {
+	assert := assert.New(t)
+	r := New()
+	if err := r.Insert(0, "world"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Nil(r.Insert(-10, "hello "))
+	assert.Nil(r.Insert(100, "!"))
+	assert.Equal("hello world!", r.String())
+	assert.Nil(r.EraseAt(-3, -2))
+	assert.Nil(r.EraseAt(11, 100))
+	assert.Equal("hello world", r.String())
+	assert.Nil(r.Replace(-1, 5, []byte("HELLO")))
+	assert.Equal("HELLO world", r.String())
+	assert.Nil(r.Validate())
+
+	assert.Equal(-1, r.ByteOffset(-1))
+	assert.Equal(-1, r.ByteOffset(12))
+	offset, err := r.ByteOffsetE(12)
+	assert.True(errors.Is(err, ErrOutOfRange))
+	assert.Equal(-1, offset)
+	offset, err = r.ByteOffsetE(11)
+	assert.Nil(err)
+	assert.Equal(11, offset)
+
+	// so do the UTF-16 methods
+	assert.Nil(r.InsertUTF16(-1, "<"))
+	assert.Nil(r.InsertUTF16(100, ">"))
+	assert.Equal("<HELLO world>", r.String())
+	assert.Nil(r.EraseAtUTF16(100, 1))
+	assert.Nil(r.EraseAtUTF16(12, 100))
+	assert.Equal("<HELLO world", r.String())
+	assert.Nil(r.EraseAtUTF16(-3, 1))
+	assert.Equal("HELLO world", r.String())
+	assert.Nil(r.Validate())
+	assert.True(errors.Is(NewScanner(r).SeekRune(-1), ErrOutOfRange))
+	_, err = r.ReadAt(make([]byte, 1), -1)
+	assert.True(errors.Is(err, ErrOutOfRange))
+	_, _, err = r.Before(12, unicode.IsSpace)
+	assert.True(errors.Is(err, ErrOutOfRange))
+
+	// scanners clamp the point
+	s := NewScannerAt(r, -5)
+	char, _, err := s.ReadRune()
+	assert.Nil(err)
+	assert.Equal('H', char)
+	rs := NewReverseScanner(r, 100)
+	char, _, err = rs.ReadRune()
+	assert.Nil(err)
+	assert.Equal('d', char)
+}
+
 func TestBefore(t *testing.T) {
 	// short
 	r := New()
@@ -196,6 +341,27 @@ func TestBefore(t *testing.T) {
 	if char != ' ' {
 		t.Errorf("Expected char to be ' '. Got %q instead", char)
 	}
+
+	// multibyte runes across knots
+	r = New()
+	if err := r.Insert(0, "你好 "+strings.Repeat("世界", 100)); err != nil {
+		t.Fatal(err)
+	}
+	before, char, err = r.Before(150, unicode.IsSpace)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, before)
+	assert.Equal(t, ' ', char)
+
+	// the rune at the point
+	before, _, err = r.Before(2, unicode.IsSpace)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, before)
+
+	// nothing matches
+	_, _, err = r.Before(2, unicode.IsDigit)
+	assert.Equal(t, ErrNotFound, err)
+	_, _, err = r.Before(500, unicode.IsSpace)
+	assert.NotNil(t, err)
 }
 
 func TestByteOffset(t *testing.T) {
@@ -249,6 +415,270 @@ func TestRope_WriteAppend(t *testing.T) {
 	assert.Equal(t, len(expected), written1+written2)
 }
 
+func TestRope_ReadAt(t *testing.T) {
+	assert := assert.New(t)
+	str := strings.Repeat("Hello 世界!\n", 100)
+	r := NewFromBytes([]byte(str))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+			for j := 0; j < 200; j++ {
+				off := rnd.Intn(len(str) + 1)
+				p := make([]byte, rnd.Intn(300))
+				n, err := r.ReadAt(p, int64(off))
+				expected := str[off:min(off+len(p), len(str))]
+				assert.Equal(expected, string(p[:n]))
+				if n < len(p) {
+					assert.Equal(io.EOF, err)
+				} else {
+					assert.Nil(err)
+				}
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+
+	_, err := r.ReadAt(make([]byte, 1), -1)
+	assert.NotNil(err)
+}
+
+func TestRope_WriteTo(t *testing.T) {
+	assert := assert.New(t)
+	str := strings.Repeat("Hello 世界!\n", 100)
+	r := NewFromBytes([]byte(str))
+
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	assert.Nil(err)
+	assert.Equal(int64(len(str)), n)
+	assert.Equal(str, buf.String())
+
+	// a scanner writes what is left unread
+	s := NewScannerAt(r, 500)
+	buf.Reset()
+	n, err = io.Copy(&buf, s)
+	assert.Nil(err)
+	assert.Equal(string([]rune(str)[500:]), buf.String())
+	assert.Equal(int64(buf.Len()), n)
+
+	errWrite := errors.New("write error")
+	n, err = r.WriteTo(errWriter{errWrite})
+	assert.Equal(errWrite, err)
+	assert.Equal(int64(0), n)
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write(p []byte) (int, error) { return 0, w.err }
+
+func TestRope_RuneOffset(t *testing.T) {
+	r := New()
+	s := "你好world. This is a longer sentence with 世界 to make sure that several knots are used"
+	if err := r.Insert(0, s); err != nil {
+		t.Fatal(err)
+	}
+	for i := range []rune(s) {
+		b := r.ByteOffset(i)
+		assert.Equal(t, i, r.RuneOffset(b))
+	}
+	assert.Equal(t, 0, r.RuneOffset(1), "Offsets in the middle of a rune are moved to the start of the rune")
+	assert.Equal(t, 1, r.RuneOffset(5))
+	assert.Equal(t, r.Runes(), r.RuneOffset(r.Size()))
+	assert.Equal(t, -1, r.RuneOffset(r.Size()+1))
+
+	// ill-formed UTF-8 is counted as utf8.RuneCount counts it: stray continuation bytes and truncated sequences are runes of their own
+	r = New()
+	bad := "a\xb0\xe4\xb8b\xff\xfe\x80c你" + strings.Repeat("\xe4\xb8\xb0\x80", 30)
+	if err := r.InsertBytes(0, []byte(bad)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, utf8.RuneCountInString(bad), r.Runes())
+	for i := 0; i <= r.Runes(); i++ {
+		b := r.ByteOffset(i)
+		assert.Equal(t, i, r.RuneOffset(b), "RuneOffset(ByteOffset(%d))", i)
+	}
+	assert.Equal(t, 1, r.RuneOffset(1))
+	assert.Equal(t, 9, r.RuneOffset(10), "Offsets in the middle of a rune are moved to the start of the rune")
+}
+
+func TestRope_ByteOps(t *testing.T) {
+	const alphabet = "ab\n你好 😀"
+	ar := []rune(alphabet)
+	rnd := rand.New(rand.NewSource(1337))
+
+	r := New()
+	var bs []byte
+	for i := 0; i < 500; i++ {
+		at := rnd.Intn(len(bs) + 1)
+		switch rnd.Intn(3) {
+		case 0:
+			n := rnd.Intn(100)
+			start := r.ByteOffset(r.RuneOffset(at)) // rounded down
+			if err := r.EraseBytes(at, n); err != nil {
+				t.Fatal(err)
+			}
+			end := len(bs)
+			if at+n < len(bs) {
+				end = at + n
+				for end > 0 && !utf8.RuneStart(bs[end]) {
+					end--
+				}
+			}
+			if end > start {
+				bs = append(bs[:start:start], bs[end:]...)
+			}
+		default:
+			ins := make([]rune, rnd.Intn(50))
+			for j := range ins {
+				ins[j] = ar[rnd.Intn(len(ar))]
+			}
+			for at < len(bs) && !utf8.RuneStart(bs[at]) {
+				at--
+			}
+			data := []byte(string(ins))
+			if err := r.InsertAtByte(at, data); err != nil {
+				t.Fatal(err)
+			}
+			bs = append(bs[:at:at], append(data, bs[at:]...)...)
+		}
+		if !assert.Equal(t, string(bs), r.String()) {
+			t.FailNow()
+		}
+
+		a, b := rnd.Intn(len(bs)+1), rnd.Intn(len(bs)+1)
+		if a > b {
+			a, b = b, a
+		}
+		for a > 0 && a < len(bs) && !utf8.RuneStart(bs[a]) {
+			a--
+		}
+		for b > 0 && b < len(bs) && !utf8.RuneStart(bs[b]) {
+			b--
+		}
+		assert.Equal(t, string(bs[a:b]), string(r.SubstrByteRange(a, b)))
+	}
+	validRope(t, r)
+}
+
+func TestRope_Replace(t *testing.T) {
+	const alphabet = "ab\n你好 😀"
+	ar := []rune(alphabet)
+	rnd := rand.New(rand.NewSource(1337))
+
+	r := New()
+	var model []rune
+	for i := 0; i < 1000; i++ {
+		point := rnd.Intn(len(model) + 1)
+		n := rnd.Intn(20)
+		if rnd.Intn(10) == 0 {
+			n = rnd.Intn(200)
+		}
+		ins := make([]rune, rnd.Intn(30))
+		if rnd.Intn(10) == 0 {
+			ins = make([]rune, rnd.Intn(200))
+		}
+		for j := range ins {
+			ins[j] = ar[rnd.Intn(len(ar))]
+		}
+
+		if err := r.Replace(point, n, []byte(string(ins))); err != nil {
+			t.Fatal(err)
+		}
+		n = min(n, len(model)-point)
+		model = append(model[:point:point], append(ins, model[point+n:]...)...)
+		if !assert.Equal(t, string(model), r.String()) {
+			t.FailNow()
+		}
+	}
+	validRope(t, r)
+}
+
+func TestRope_Replace_Hooks(t *testing.T) {
+	assert := assert.New(t)
+	r := New()
+	r.Insert(0, "Hello World")
+	h := NewHistory(r)
+	left := r.NewMark(6, LeftGravity)
+	inside := r.NewMark(8, RightGravity)
+	after := r.NewMark(11, LeftGravity)
+
+	var changes []Change
+	r.Subscribe(func(c Change) { changes = append(changes, c) })
+
+	if err := r.Replace(6, 5, []byte("世界\nand all")); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("Hello 世界\nand all", r.String())
+	assert.Equal(6, left.Pos())
+	// the marks in the replaced runes are moved as if the runes were erased, then the new runes inserted
+	assert.Equal(16, inside.Pos())
+	assert.Equal(6, after.Pos())
+	if assert.Len(changes, 1) {
+		c := changes[0]
+		assert.Equal(6, c.Point)
+		assert.Equal(5, c.Erased)
+		assert.Equal(5, c.ErasedBytes)
+		assert.Equal("世界\nand all", string(c.Inserted))
+		assert.Equal(Position{0, 6}, c.Start)
+		assert.Equal(Position{0, 11}, c.OldEnd)
+		assert.Equal(Position{1, 7}, c.NewEnd)
+	}
+
+	// the replacement is undone in one step
+	if err := h.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("Hello World", r.String())
+	assert.False(h.CanUndo())
+	if err := h.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("Hello 世界\nand all", r.String())
+}
+
+func TestAfter(t *testing.T) {
+	assert := assert.New(t)
+	r := New()
+	if err := r.Insert(0, "Hello World"); err != nil {
+		t.Fatal(err)
+	}
+
+	after, char, err := r.After(1, unicode.IsSpace)
+	assert.Nil(err)
+	assert.Equal(5, after)
+	assert.Equal(' ', char)
+
+	// the rune at the point
+	after, _, err = r.After(5, unicode.IsSpace)
+	assert.Nil(err)
+	assert.Equal(5, after)
+
+	// multibyte runes across knots
+	r = New()
+	if err := r.Insert(0, strings.Repeat("世界", 100)+" 你好"); err != nil {
+		t.Fatal(err)
+	}
+	after, char, err = r.After(3, unicode.IsSpace)
+	assert.Nil(err)
+	assert.Equal(200, after)
+	assert.Equal(' ', char)
+
+	_, _, err = r.After(201, unicode.IsSpace)
+	assert.Equal(ErrNotFound, err)
+	_, _, err = r.After(-1, unicode.IsSpace)
+	assert.NotNil(err)
+
+	point, err := r.FindFunc(-10, func(r rune) bool { return r == '你' })
+	assert.Nil(err)
+	assert.Equal(201, point)
+	_, err = r.FindFunc(500, unicode.IsSpace)
+	assert.Equal(ErrNotFound, err)
+}
+
 func ExampleBasic() {
 	r := New()
 	_ = r.Insert(0, "Hello World. This is a long sentence. The purpose of this long sentence is to make sure there is more than BucketSize worth of runes")
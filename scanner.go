@@ -21,56 +21,114 @@ type Scanner struct {
 }
 
 // NewScanner creates a new scanner.
-func NewScanner(r *Rope) *Scanner {
-	sl := skiplist{r: r}
+func NewScanner(r *Rope) *Scanner { return NewScannerAt(r, 0) }
+
+// NewScannerAt creates a new scanner which starts reading at the point. The point is clamped to the bounds of the rope.
+func NewScannerAt(r *Rope, point int) *Scanner {
 	s := &Scanner{Rope: r}
+	s.seekRune(clamp(point, 0, r.runes))
+	return s
+}
+
+// Len returns the number of bytes unread
+func (s *Scanner) Len() int { return max(s.size-s.readBytes, 0) }
+
+// Seek implements io.Seeker. The offset is in bytes. Seeking to an offset in the middle of a UTF-8 sequence is allowed,
+// in which case the next rune read is utf8.RuneError. Seeking past the end of the rope is allowed too, and subsequent reads return io.EOF.
+func (s *Scanner) Seek(offset int64, whence int) (int64, error) {
+	var at int64
+	switch whence {
+	case io.SeekStart:
+		at = offset
+	case io.SeekCurrent:
+		at = int64(s.readBytes) + offset
+	case io.SeekEnd:
+		at = int64(s.size) + offset
+	default:
+		return 0, errors.New("Invalid whence")
+	}
+	if at < 0 {
+		return 0, ErrOutOfRange
+	}
+	if at >= int64(s.size) {
+		s.k, s.offset, s.prevK = nil, 0, nil
+		s.readBytes = int(at)
+		return at, nil
+	}
+
+	sl := skiplist{r: s.Rope}
+	var offsetBytes int
 	var err error
-	if s.k, s.offset, _, err = sl.find(0); err != nil {
-		panic(err)
+	if s.k, _, offsetBytes, _, err = sl.findByte(int(at)); err != nil {
+		return 0, err
+	}
+	// findByte moves the offset to the start of the rune, but the scanner is placed at the exact byte.
+	start := sl.s[s.Rope.Head.height-1].skipped
+	s.offset = offsetBytes + int(at) - start
+	s.readBytes = int(at)
+	s.prevK = nil
+	s.next()
+	return at, nil
+}
+
+// SeekRune moves the scanner to the point, so that the next rune read is the rune at the point. ErrOutOfRange is returned if the point is out of bounds.
+func (s *Scanner) SeekRune(point int) error {
+	if point < 0 || point > s.runes {
+		return ErrOutOfRange
 	}
+	s.seekRune(point)
+	return nil
+}
 
-	// first block may not be used
-	for s.k.used-s.offset <= 0 {
-		// go to next block
+// seekRune moves the scanner to the point, which has to be within the bounds of the rope.
+func (s *Scanner) seekRune(point int) {
+	sl := skiplist{r: s.Rope}
+	var skippedBytes int
+	s.k, s.offset, skippedBytes = sl.at(point)
+	s.readBytes = skippedBytes + s.offset
+	s.prevK = nil
+	s.next()
+}
+
+// next moves the scanner to the next knot if the current knot has been read.
+func (s *Scanner) next() {
+	for s.k != nil && s.offset >= s.k.used {
+		s.offset -= s.k.used
 		s.prevK = s.k
 		s.k = s.k.nexts[0].knot
 	}
-	return s
 }
 
-// Len returns the number of bytes unread
-func (s *Scanner) Len() int { return s.size - s.readBytes }
-
 // Read implements io.Reader. It reads up to len(p) bytes into p. The Scanner is a stateful reader,
 // meaning it will keep track of how many bytes has been read.
 func (s *Scanner) Read(p []byte) (n int, err error) {
 	if s.readBytes >= s.size || s.k == nil {
 		return 0, io.EOF
 	}
-
-	l := len(p)
-	used := s.k.used - s.offset
-	n = copy(p, s.k.data[s.offset:s.k.used])
-	if l <= used {
-		s.offset += n
-		s.readBytes += n
-		return
+	for n < len(p) && s.k != nil {
+		copied := copy(p[n:], s.k.data[s.offset:s.k.used])
+		n += copied
+		s.offset += copied
+		s.readBytes += copied
+		s.next()
 	}
+	return n, nil
+}
 
-	// TODO: recursive reading is simple to understand but performance is ??? .
-	remainder := l - n
-	if remainder > 0 {
-		s.offset = 0
-
-		var n2 int
-		s.k = s.k.nexts[0].knot
-		if n2, err = s.Read(p[remainder:]); err != nil && err != io.EOF {
+// WriteTo implements io.WriterTo. It writes the unread bytes to the writer, a knot at a time.
+func (s *Scanner) WriteTo(w io.Writer) (n int64, err error) {
+	for s.k != nil && s.readBytes < s.size {
+		var written int
+		written, err = w.Write(s.k.data[s.offset:s.k.used])
+		n += int64(written)
+		s.offset += written
+		s.readBytes += written
+		s.next()
+		if err != nil {
 			return n, err
 		}
-		err = nil
-		n += n2
 	}
-	return
+	return n, nil
 }
 
 // ReadByte  implements io.ByteReader
@@ -81,11 +139,7 @@ func (s *Scanner) ReadByte() (byte, error) {
 	retVal := s.k.data[s.offset]
 	s.offset++
 	s.readBytes++
-	if s.offset >= s.k.used {
-		s.prevK = s.k
-		s.k = s.k.nexts[0].knot
-		s.offset = 0
-	}
+	s.next()
 	return retVal, nil
 }
 
@@ -99,30 +153,106 @@ func (s *Scanner) ReadRune() (rune, int, error) {
 	s.lastSize = size
 	s.offset += size
 	s.readBytes += size
-	if s.offset >= s.k.used {
-		s.prevK = s.k
-		s.k = s.k.nexts[0].knot
-		s.offset = 0
-	}
+	s.next()
 	return r, size, nil
 }
 
 // UnreadRune implements io.RuneScanner.
 func (s *Scanner) UnreadRune() error {
-	if s.offset == 0 && s.prevK == nil {
+	if s.readBytes == 0 {
 		return ErrSOF
 	}
-	s.readBytes -= s.lastSize
+	if s.readBytes > s.size {
+		// the scanner was moved past the end
+		if _, err := s.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
 	if s.offset == 0 {
+		if s.prevK == nil {
+			// find the knot before the current one
+			sl := skiplist{r: s.Rope}
+			var err error
+			if s.prevK, _, _, _, err = sl.findByte(s.readBytes); err != nil {
+				return err
+			}
+		}
 		s.k = s.prevK
 		s.prevK = nil
-		s.offset = s.k.used - s.lastSize
-	} else {
-		s.offset -= s.lastSize
-		if s.offset < 0 {
-			// TODO: this is unlikely to happen
-			return io.ErrShortBuffer
-		}
+		s.offset = s.k.used
+	}
+	// the size of the previous rune has to be recalculated because UnreadRune may be called repeatedly
+	_, s.lastSize = utf8.DecodeLastRune(s.k.data[:s.offset])
+	if s.lastSize > s.offset {
+		// TODO: this is unlikely to happen
+		return io.ErrShortBuffer
 	}
+	s.offset -= s.lastSize
+	s.readBytes -= s.lastSize
 	return nil
 }
+
+// ReverseScanner is a linear scanner over a *Rope which returns runes backwards, from a point towards the start of the rope.
+//
+// Knots are only linked forwards, so the previous knot is found with a search from the head whenever a knot has been read.
+// This costs O(log n) for every knot read.
+type ReverseScanner struct {
+	*Rope
+	k      *knot
+	offset int // how many bytes of the current knot are unread
+	start  int // the byte offset of the current knot
+}
+
+// NewReverseScanner creates a new reverse scanner which starts reading at the rune before the point.
+// The point is clamped to the bounds of the rope.
+func NewReverseScanner(r *Rope, point int) *ReverseScanner {
+	s := &ReverseScanner{Rope: r}
+	sl := skiplist{r: r}
+	var skippedBytes int
+	s.k, s.offset, skippedBytes = sl.at(clamp(point, 0, r.runes))
+	s.start = skippedBytes
+	return s
+}
+
+// Len returns the number of bytes unread, which are the bytes before the scanner.
+func (s *ReverseScanner) Len() int { return s.start + s.offset }
+
+// ReadByte implements io.ByteReader. It reads the byte before the scanner.
+func (s *ReverseScanner) ReadByte() (byte, error) {
+	if !s.prev() {
+		return 0, io.EOF
+	}
+	s.offset--
+	return s.k.data[s.offset], nil
+}
+
+// ReadRune implements io.RuneReader. It reads the rune before the scanner and returns its size in bytes.
+func (s *ReverseScanner) ReadRune() (rune, int, error) {
+	if !s.prev() {
+		return -1, -1, io.EOF
+	}
+	r, size := utf8.DecodeLastRune(s.k.data[:s.offset])
+	s.offset -= size
+	return r, size, nil
+}
+
+// prev moves the scanner to the previous knot if the current knot has been read. It returns false if there is nothing left to read.
+func (s *ReverseScanner) prev() bool {
+	if s.offset > 0 {
+		return true
+	}
+	if s.start == 0 {
+		return false
+	}
+
+	// the search stays in the knot before the byte offset if the offset is at the start of a knot
+	sl := skiplist{r: s.Rope}
+	k, _, offsetBytes, _, err := sl.findByte(s.start)
+	if err != nil {
+		return false
+	}
+	s.k = k
+	s.offset = offsetBytes
+	s.start -= offsetBytes
+	return s.offset > 0
+}
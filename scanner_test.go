@@ -3,7 +3,11 @@ package skiprope
 import (
 	"fmt"
 	"io"
+	"strings"
 	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func ExampleScanner() {
@@ -211,3 +215,132 @@ func ExampleScanner_ReadByte() {
 	// 'l'
 	// 'd'
 }
+
+func TestNewScannerAt(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewScannerAt(New(), 0)
+	_, _, err := s.ReadRune()
+	assert.Equal(io.EOF, err)
+
+	str := strings.Repeat("Hello 世界!\n", 100)
+	runes := []rune(str)
+	r := NewFromBytes([]byte(str))
+	for _, point := range []int{0, 1, 6, 7, 63, 64, 500, len(runes) - 1, len(runes), len(runes) + 10} {
+		s := NewScannerAt(r, point)
+		rest, err := io.ReadAll(s)
+		assert.Nil(err)
+		assert.Equal(string(runes[min(point, len(runes)):]), string(rest), "NewScannerAt(%d)", point)
+	}
+}
+
+func TestScanner_Seek(t *testing.T) {
+	assert := assert.New(t)
+	str := strings.Repeat("Hello 世界!\n", 100)
+	r := NewFromBytes([]byte(str))
+	s := NewScanner(r)
+
+	for _, at := range []int{0, 5, 6, 7, 8, 100, 63, 64, 65, len(str) - 1, len(str)} {
+		n, err := s.Seek(int64(at), io.SeekStart)
+		assert.Nil(err)
+		assert.Equal(int64(at), n)
+		assert.Equal(len(str)-at, s.Len())
+
+		rest, err := io.ReadAll(s)
+		assert.Nil(err)
+		assert.Equal(str[at:], string(rest), "Seek(%d)", at)
+	}
+
+	// whence
+	s.Seek(10, io.SeekStart)
+	n, err := s.Seek(-4, io.SeekCurrent)
+	assert.Nil(err)
+	assert.Equal(int64(6), n)
+	char, _, _ := s.ReadRune()
+	assert.Equal('世', char)
+
+	n, err = s.Seek(-2, io.SeekEnd)
+	assert.Nil(err)
+	assert.Equal(int64(len(str)-2), n)
+	b, _ := s.ReadByte()
+	assert.Equal(byte('!'), b)
+
+	// the middle of a rune
+	s.Seek(7, io.SeekStart)
+	char, size, _ := s.ReadRune()
+	assert.Equal(utf8.RuneError, char)
+	assert.Equal(1, size)
+
+	// past the end
+	n, err = s.Seek(10, io.SeekEnd)
+	assert.Nil(err)
+	assert.Equal(int64(len(str)+10), n)
+	_, err = s.ReadByte()
+	assert.Equal(io.EOF, err)
+	assert.Nil(s.UnreadRune())
+	char, _, _ = s.ReadRune()
+	assert.Equal('\n', char)
+
+	_, err = s.Seek(-1, io.SeekStart)
+	assert.NotNil(err)
+}
+
+func TestScanner_SeekRune(t *testing.T) {
+	assert := assert.New(t)
+	str := strings.Repeat("Hello 世界!\n", 100)
+	runes := []rune(str)
+	r := NewFromBytes([]byte(str))
+	s := NewScanner(r)
+
+	for _, point := range []int{500, 0, 6, 7, 63, len(runes)} {
+		assert.Nil(s.SeekRune(point))
+		var read []rune
+		for char, _, err := s.ReadRune(); err == nil; char, _, err = s.ReadRune() {
+			read = append(read, char)
+		}
+		assert.Equal(string(runes[point:]), string(read))
+
+		// and back again
+		var unread int
+		for s.UnreadRune() == nil {
+			unread++
+		}
+		assert.Equal(len(runes), unread)
+	}
+	assert.NotNil(s.SeekRune(-1))
+	assert.NotNil(s.SeekRune(len(runes) + 1))
+}
+
+func TestReverseScanner(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewReverseScanner(New(), 0)
+	_, _, err := s.ReadRune()
+	assert.Equal(io.EOF, err)
+
+	str := strings.Repeat("Hello 世界!\n", 100)
+	runes := []rune(str)
+	r := NewFromBytes([]byte(str))
+	for _, point := range []int{0, 1, 6, 7, 63, 64, 500, len(runes) - 1, len(runes), len(runes) + 10} {
+		s := NewReverseScanner(r, point)
+		point = min(point, len(runes))
+		assert.Equal(len(string(runes[:point])), s.Len())
+
+		var read []rune
+		for char, _, err := s.ReadRune(); err == nil; char, _, err = s.ReadRune() {
+			read = append(read, char)
+		}
+		for i := range read {
+			assert.Equal(runes[point-1-i], read[i])
+		}
+		assert.Equal(point, len(read))
+		assert.Equal(0, s.Len())
+	}
+
+	s = NewReverseScanner(r, 7)
+	b, _ := s.ReadByte()
+	assert.Equal(str[6+2], b)
+	char, size, _ := s.ReadRune()
+	assert.Equal(utf8.RuneError, char)
+	assert.Equal(1, size)
+}
@@ -2,26 +2,29 @@ package skiprope
 
 import (
 	"unicode/utf8"
-
-	"errors"
 	// "log"
 )
 
 // skiplist is a data structure for searching... it's the "skiplist" part of things
+//
+// Each element of s is the last knot visited at that height during a search. The counts of each element
+// are the distance between the start of that knot and the searched point.
 type skiplist struct {
 	r *Rope
 	s [MaxHeight]skipknot
 }
 
-// newKnot will accept a []byte of BucketSize or less
+// newKnot will accept a []byte of the rope's bucket size or less. Larger data, which may come from a knot of another rope, gets a larger knot.
 func (s *skiplist) newKnot(data []byte, runeCount int) {
 	maxHeight := s.r.Head.height
-	newHeight := randInt()
+	newHeight := s.r.randInt()
 
 	byteCount := len(data)
-	k := newKnot(newHeight)
+	lineCount := countLines(data)
+	unitCount := countUTF16(data)
+	k := newKnot(newHeight, s.r.epoch, max(s.r.bucketSize, byteCount))
 	k.used = byteCount
-	copy(k.data[0:], data)
+	copy(k.data, data)
 
 	// the rest of the reason why anyone bothers to take accounting classes
 	for maxHeight <= newHeight {
@@ -38,36 +41,133 @@ func (s *skiplist) newKnot(data []byte, runeCount int) {
 		k.nexts[i].knot = prev.knot
 		k.nexts[i].skipped = byteCount + prev.skipped - s.s[i].skipped
 		k.nexts[i].skippedRunes = runeCount + prev.skippedRunes - s.s[i].skippedRunes
+		k.nexts[i].skippedLines = lineCount + prev.skippedLines - s.s[i].skippedLines
+		k.nexts[i].skippedUTF16 = unitCount + prev.skippedUTF16 - s.s[i].skippedUTF16
 
 		s.s[i].knot.nexts[i].knot = k
 		s.s[i].knot.nexts[i].skipped = s.s[i].skipped
 		s.s[i].knot.nexts[i].skippedRunes = s.s[i].skippedRunes
+		s.s[i].knot.nexts[i].skippedLines = s.s[i].skippedLines
+		s.s[i].knot.nexts[i].skippedUTF16 = s.s[i].skippedUTF16
 
 		// move search to end of newly inserted node
 		s.s[i].knot = k
 		s.s[i].skipped = byteCount
 		s.s[i].skippedRunes = runeCount
+		s.s[i].skippedLines = lineCount
+		s.s[i].skippedUTF16 = unitCount
 	}
 
 	for i := newHeight; i < maxHeight; i++ {
 		s.s[i].knot.nexts[i].skipped += byteCount
 		s.s[i].knot.nexts[i].skippedRunes += runeCount
+		s.s[i].knot.nexts[i].skippedLines += lineCount
+		s.s[i].knot.nexts[i].skippedUTF16 += unitCount
 		s.s[i].skipped += byteCount
 		s.s[i].skippedRunes += runeCount
+		s.s[i].skippedLines += lineCount
+		s.s[i].skippedUTF16 += unitCount
 	}
 	s.r.size += byteCount
 	s.r.runes += runeCount
+	s.r.lines += lineCount
+	s.r.codeUnits += unitCount
+}
+
+// own clones the knots that are shared with other ropes, up to and including the first knot that starts after the point.
+//
+// Knots are only linked forwards, so a shared knot can only be replaced by its clone if all the knots before it
+// belong to the rope as well. This means the knots that belong to the rope always form a prefix of the rope.
+func (s *skiplist) own(point int) {
+	if !s.r.shared {
+		return
+	}
+
+	// skip the knots that already belong to the rope. last holds the last knot visited at each height.
+	var last [MaxHeight]*knot
+	k := &s.r.Head
+	var skippedRunes int
+	for height := k.height - 1; height >= 0; height-- {
+		for next := k.nexts[height].knot; next != nil && next.epoch == s.r.epoch; next = k.nexts[height].knot {
+			skippedRunes += k.nexts[height].skippedRunes
+			k = next
+		}
+		last[height] = k
+	}
+
+	for skippedRunes <= point {
+		next := k.nexts[0].knot
+		if next == nil {
+			// every knot belongs to the rope now
+			s.r.shared = false
+			return
+		}
+		skippedRunes += k.nexts[0].skippedRunes
+
+		clone := next.clone(s.r.epoch)
+		for i := 0; i < clone.height; i++ {
+			last[i].nexts[i].knot = clone
+			last[i] = clone
+		}
+		k = clone
+	}
 }
 
 // find is the generic skip list finding function. It returns the offsets and skipped bytes.
 func (s *skiplist) find(point int) (retVal *knot, offsetBytes, skippedBytes int, err error) {
-	if point > s.r.runes {
-		return nil, -1, -1, errors.New("Index out of bounds")
+	if point < 0 || point > s.r.runes {
+		return nil, -1, -1, ErrOutOfRange
 	}
 
-	k := &s.r.Head
-	height := k.height - 1
-	offset := point
+	k, offsetBytes, skippedBytes := s.at(point)
+	return k, offsetBytes, skippedBytes, nil
+}
+
+// at is like find, for points which are known to be within the bounds of the rope.
+func (s *skiplist) at(point int) (retVal *knot, offsetBytes, skippedBytes int) {
+	return s.descend(&s.r.Head, s.r.Head.height-1, point, skipknot{})
+}
+
+// findFrom is like find, except that it continues from the point that the search path is at, instead of starting from the head.
+// The point cannot be before the point of the search path. Finding points from left to right this way costs O(log d) each,
+// where d is the distance between the points.
+func (s *skiplist) findFrom(point int) (retVal *knot, offsetBytes int, err error) {
+	top := s.r.Head.height - 1
+	for i := 1; i <= top; i++ {
+		// the head may have grown since the search
+		if s.s[i].knot == nil {
+			s.s[i] = s.s[i-1]
+		}
+	}
+
+	// the topmost level of the search path starts at the head, so it holds the counts before the point of the search path
+	from := s.s[top]
+	if point > s.r.runes || point < from.skippedRunes {
+		return nil, -1, ErrOutOfRange
+	}
+
+	// go up until the search path spans the point
+	height := 0
+	for height < top && s.s[height].knot.nexts[height].skippedRunes-s.s[height].skippedRunes < point-from.skippedRunes {
+		height++
+	}
+	for i := height; i <= top; i++ {
+		s.s[i].skipped = from.skipped - s.s[i].skipped
+		s.s[i].skippedRunes = from.skippedRunes - s.s[i].skippedRunes
+		s.s[i].skippedLines = from.skippedLines - s.s[i].skippedLines
+		s.s[i].skippedUTF16 = from.skippedUTF16 - s.s[i].skippedUTF16
+	}
+	abs := s.s[height]
+	retVal, offsetBytes, _ = s.descend(abs.knot, height, point-abs.skippedRunes, abs)
+	return retVal, offsetBytes, nil
+}
+
+// descend searches for the point from the knot k downwards, starting at the given height. The point is offset runes
+// after the start of k, and abs holds the counts before k. The search path above the height has to hold the counts before
+// each knot.
+func (s *skiplist) descend(k *knot, height, offset int, abs skipknot) (retVal *knot, offsetBytes, skippedBytes int) {
+	skippedBytes = abs.skipped
+	skippedRunes, skippedLines, skippedUTF16 := abs.skippedRunes, abs.skippedLines, abs.skippedUTF16
 
 	for {
 		var skip int
@@ -79,80 +179,178 @@ func (s *skiplist) find(point int) (retVal *knot, offsetBytes, skippedBytes int,
 				break
 			}
 			skippedBytes += k.nexts[height].skipped
+			skippedRunes += skip
+			skippedLines += k.nexts[height].skippedLines
+			skippedUTF16 += k.nexts[height].skippedUTF16
 			k = k.nexts[height].knot
 		} else {
 			// go down
-			s.s[height].skippedRunes = offset
-			s.s[height].skipped = skippedBytes
-			s.s[height].knot = k
+			s.s[height] = skipknot{k, skippedBytes, skippedRunes, skippedLines, skippedUTF16}
 			if height == 0 {
 				break
 			}
 			height--
 		}
 	}
-	offsetBytes = byteOffset(k.data[:], offset)
-	return k, offsetBytes, skippedBytes, nil
+	offsetBytes = byteOffset(k.data[:k.used], offset)
+	s.relativise(k, offsetBytes, offset, skipknot{nil, skippedBytes, skippedRunes, skippedLines, skippedUTF16})
+	return k, offsetBytes, skippedBytes
+}
+
+// findByte is like find, except that the point is given as a byte offset. Offsets that fall in the middle of a
+// UTF-8 sequence are moved to the start of the sequence. It returns the offsets in runes and bytes into the knot,
+// and the number of runes skipped before the knot.
+func (s *skiplist) findByte(at int) (retVal *knot, offsetRunes, offsetBytes, skippedRunes int, err error) {
+	if at < 0 || at > s.r.size {
+		return nil, -1, -1, -1, ErrOutOfRange
+	}
+
+	k := &s.r.Head
+	height := k.height - 1
+	offsetBytes = at
+	var skippedBytes, skippedLines, skippedUTF16 int
+
+	for {
+		skip := k.nexts[height].skipped
+		if offsetBytes > skip && k.nexts[height].knot != nil {
+			// go right
+			offsetBytes -= skip
+			skippedBytes += skip
+			skippedRunes += k.nexts[height].skippedRunes
+			skippedLines += k.nexts[height].skippedLines
+			skippedUTF16 += k.nexts[height].skippedUTF16
+			k = k.nexts[height].knot
+		} else {
+			// go down
+			s.s[height] = skipknot{k, skippedBytes, skippedRunes, skippedLines, skippedUTF16}
+			if height == 0 {
+				break
+			}
+			height--
+		}
+	}
+	// the runes are decoded from the start of the knot, so that they are counted the same way as utf8.RuneCount counts them:
+	// a continuation byte which does not follow the start of a sequence is a rune of its own.
+	for i := 0; i < offsetBytes; offsetRunes++ {
+		_, size := utf8.DecodeRune(k.data[i:k.used])
+		if i+size > offsetBytes {
+			offsetBytes = i
+			break
+		}
+		i += size
+	}
+	if offsetBytes == 0 && at != skippedBytes {
+		// the point was moved to the start of k, so the search path has to end at the previous knot instead.
+		return s.findByte(skippedBytes)
+	}
+	s.relativise(k, offsetBytes, offsetRunes, skipknot{nil, skippedBytes, skippedRunes, skippedLines, skippedUTF16})
+	return k, offsetRunes, offsetBytes, skippedRunes, nil
 }
 
-// find2 is a method that finds blocks for insertion and deletion. No counts for byteoffsets required.
+// relativise converts the counts of the search path from the absolute counts before each knot to the distance between
+// the start of each knot and the searched point. The point is offsetBytes (or offsetRunes) into k, and abs holds
+// the absolute counts before k.
+func (s *skiplist) relativise(k *knot, offsetBytes, offsetRunes int, abs skipknot) {
+	abs.skipped += offsetBytes
+	abs.skippedRunes += offsetRunes
+	abs.skippedLines += countLines(k.data[:offsetBytes])
+	abs.skippedUTF16 += countUTF16(k.data[:offsetBytes])
+	for i := 0; i < s.r.Head.height; i++ {
+		s.s[i].skipped = abs.skipped - s.s[i].skipped
+		s.s[i].skippedRunes = abs.skippedRunes - s.s[i].skippedRunes
+		s.s[i].skippedLines = abs.skippedLines - s.s[i].skippedLines
+		s.s[i].skippedUTF16 = abs.skippedUTF16 - s.s[i].skippedUTF16
+	}
+}
+
+// find2 is a method that finds blocks for insertion and deletion.
 func (s *skiplist) find2(point int) (retVal *knot, err error) {
-	if point > s.r.runes {
-		return nil, errors.New("Index out of bounds")
+	retVal, _, _, err = s.find(point)
+	return
+}
+
+// findLine finds the knot which holds the nth newline. It returns the knot, the number of newlines
+// that remain to be found in the knot, and the number of runes skipped before the knot.
+func (s *skiplist) findLine(n int) (retVal *knot, offsetLines, skippedRunes int, err error) {
+	if n < 0 || n > s.r.lines {
+		return nil, -1, -1, ErrOutOfRange
 	}
 
 	k := &s.r.Head
 	height := k.height - 1
-	offset := point
+	offsetLines = n
 
 	for {
-		var skip int
-		skip = k.nexts[height].skippedRunes
-		if offset > skip {
+		skip := k.nexts[height].skippedLines
+		if offsetLines > skip && k.nexts[height].knot != nil {
 			// go right
-			offset -= skip
-			if k.nexts[height].knot == nil {
+			offsetLines -= skip
+			skippedRunes += k.nexts[height].skippedRunes
+			k = k.nexts[height].knot
+		} else {
+			// go down
+			if height == 0 {
 				break
 			}
+			height--
+		}
+	}
+	return k, offsetLines, skippedRunes, nil
+}
+
+// findUTF16 finds the knot which holds the nth UTF-16 code unit. It returns the knot, the number of code units
+// that remain to be skipped in the knot, and the number of runes skipped before the knot.
+func (s *skiplist) findUTF16(n int) (retVal *knot, offsetUTF16, skippedRunes int, err error) {
+	if n < 0 || n > s.r.codeUnits {
+		return nil, -1, -1, ErrOutOfRange
+	}
+
+	k := &s.r.Head
+	height := k.height - 1
+	offsetUTF16 = n
+
+	for {
+		skip := k.nexts[height].skippedUTF16
+		if offsetUTF16 > skip && k.nexts[height].knot != nil {
+			// go right
+			offsetUTF16 -= skip
+			skippedRunes += k.nexts[height].skippedRunes
 			k = k.nexts[height].knot
 		} else {
 			// go down
-			s.s[height].skippedRunes = offset
-			s.s[height].knot = k
 			if height == 0 {
 				break
 			}
 			height--
 		}
 	}
-	return k, nil
+	return k, offsetUTF16, skippedRunes, nil
 }
 
-func (s *skiplist) updateOffsets(bytecount, runecount int) {
+func (s *skiplist) updateOffsets(bytecount, runecount, linecount, unitcount int) {
 	for i := 0; i < s.r.Head.height; i++ {
 		s.s[i].knot.nexts[i].skipped += bytecount
 		s.s[i].knot.nexts[i].skippedRunes += runecount
+		s.s[i].knot.nexts[i].skippedLines += linecount
+		s.s[i].knot.nexts[i].skippedUTF16 += unitcount
 	}
 }
 
 func (s *skiplist) insert(k *knot, data []byte) error {
 	offset := s.s[0].skippedRunes
-	var offsetBytes int
-	if offset > 0 {
-		offsetBytes = byteOffset(k.data[:], offset)
-	}
+	offsetBytes := s.s[0].skipped
 
 	byteCount := len(data)
 
 	// can insert?
-	canInsert := k.used+byteCount <= BucketSize
+	canInsert := k.used+byteCount <= len(k.data)
 	if !canInsert && offsetBytes == k.used {
 		next := k.nexts[0].knot
-		if next != nil && next.used+byteCount < BucketSize {
+		if next != nil && next.used+byteCount <= len(next.data) {
 			offset = 0
 			offsetBytes = 0
 			for i := 0; i < next.height; i++ {
-				s.s[i].knot = next
+				s.s[i] = skipknot{knot: next}
 			}
 			k = next
 			canInsert = true
@@ -162,41 +360,41 @@ func (s *skiplist) insert(k *knot, data []byte) error {
 
 	if canInsert {
 		// move shit
-		if byteCount < k.used {
-			copy(k.data[offset+byteCount:], k.data[offset:])
+		if offsetBytes < k.used {
+			copy(k.data[offsetBytes+byteCount:], k.data[offsetBytes:k.used])
 		}
-		copy(k.data[offset:offset+byteCount], data)
+		copy(k.data[offsetBytes:offsetBytes+byteCount], data)
 		k.used += byteCount
+		lineCount := countLines(data)
+		unitCount := countUTF16(data)
 		s.r.size += byteCount
 		s.r.runes += runeCount
+		s.r.lines += lineCount
+		s.r.codeUnits += unitCount
 		// update the rest of the search tree
-		s.updateOffsets(byteCount, runeCount)
+		s.updateOffsets(byteCount, runeCount, lineCount, unitCount)
 	} else {
 		// we'll need to add at least Knot to the rope
 
 		// we'll need to remove the end of the current node's data if this is not at the end of the current node
 		endBytes := k.used - offsetBytes
-		var endRunes int
+		var endRunes, endLines, endUTF16 int
 		if endBytes > 0 {
-			k.used = offsetBytes
 			endRunes = k.nexts[0].skippedRunes - offset
-			s.updateOffsets(-endBytes, -endRunes)
+			endLines = countLines(k.data[offsetBytes:k.used])
+			endUTF16 = countUTF16(k.data[offsetBytes:k.used])
+			k.used = offsetBytes
+			s.updateOffsets(-endBytes, -endRunes, -endLines, -endUTF16)
 			s.r.size -= endBytes
 			s.r.runes -= endRunes
+			s.r.lines -= endLines
+			s.r.codeUnits -= endUTF16
 		}
 
 		// insert new Knots containing new data
 		var dataOffset int
 		for dataOffset < len(data) {
-			var newBytes, newRunes int
-			for dataOffset+newBytes < len(data) {
-				_, width := utf8.DecodeRune(data[dataOffset+newBytes:])
-				if newBytes+width > BucketSize {
-					break
-				}
-				newBytes += width
-				newRunes++
-			}
+			newBytes, newRunes := chunk(data[dataOffset:], s.r.bucketSize)
 			// create new Knot
 			s.newKnot(data[dataOffset:dataOffset+newBytes], newRunes)
 			dataOffset += newBytes
@@ -211,45 +409,56 @@ func (s *skiplist) insert(k *knot, data []byte) error {
 }
 
 func (s *skiplist) del(k *knot, n int) {
-	s.r.runes -= n
 	offset := s.s[0].skippedRunes
 	var i int
 	for n > 0 {
-		if k == nil {
-			break
-		}
 		if offset == k.nexts[0].skippedRunes {
 			// end found. skip to the start of the next node
 			k = s.s[0].knot.nexts[0].knot
 			offset = 0
+			if k == nil {
+				break
+			}
 		}
 		size := k.nexts[0].skippedRunes
 		removed := min(n, size-offset)
 
+		leading := byteOffset(k.data[:k.used], offset)
+		removedBytes := byteOffset(k.data[leading:k.used], removed)
+		removedLines := countLines(k.data[leading : leading+removedBytes])
+		removedUTF16 := countUTF16(k.data[leading : leading+removedBytes])
 		if removed < size || k == &s.r.Head {
-			leading := byteOffset(k.data[:], offset)
-			removedBytes := byteOffset(k.data[leading:], removed)
 			if trailing := k.used - leading - removedBytes; trailing > 0 {
-				copy(k.data[offset:], k.data[offset+removedBytes:offset+removedBytes+trailing])
+				copy(k.data[leading:], k.data[leading+removedBytes:k.used])
 			}
 			k.used -= removedBytes
-			s.r.size -= removedBytes
 
 			for i = 0; i < k.height; i++ {
+				k.nexts[i].skipped -= removedBytes
 				k.nexts[i].skippedRunes -= removed
+				k.nexts[i].skippedLines -= removedLines
+				k.nexts[i].skippedUTF16 -= removedUTF16
 			}
 		} else {
 			for i = 0; i < k.height; i++ {
 				s.s[i].knot.nexts[i].knot = k.nexts[i].knot
+				s.s[i].knot.nexts[i].skipped += k.nexts[i].skipped - removedBytes
 				s.s[i].knot.nexts[i].skippedRunes += k.nexts[i].skippedRunes - removed
+				s.s[i].knot.nexts[i].skippedLines += k.nexts[i].skippedLines - removedLines
+				s.s[i].knot.nexts[i].skippedUTF16 += k.nexts[i].skippedUTF16 - removedUTF16
 			}
-			s.r.size -= k.used
 			k = k.nexts[0].knot
-
 		}
 		for ; i < s.r.Head.height; i++ {
+			s.s[i].knot.nexts[i].skipped -= removedBytes
 			s.s[i].knot.nexts[i].skippedRunes -= removed
+			s.s[i].knot.nexts[i].skippedLines -= removedLines
+			s.s[i].knot.nexts[i].skippedUTF16 -= removedUTF16
 		}
+		s.r.size -= removedBytes
+		s.r.runes -= removed
+		s.r.lines -= removedLines
+		s.r.codeUnits -= removedUTF16
 		n -= removed
 	}
 }
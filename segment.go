@@ -0,0 +1,625 @@
+package skiprope
+
+import "unicode"
+
+// WordAt returns the bounds of the word segment which the rune at the given point is in. At the end of the rope,
+// the last segment is returned. If the point is out of bounds, -1, -1 is returned.
+//
+// Segments are found with the word boundary rules of UAX #29, so a run of spaces or a punctuation mark is a segment of its own,
+// as is each ideograph. Use unicode.IsLetter or similar on the segment to tell words apart from the rest.
+func (r *Rope) WordAt(point int) (start, end int) {
+	return r.segmentAt(point, r.wordStart, r.wordBoundaries)
+}
+
+// NextWordBoundary returns the point of the first word boundary after the given point.
+// At the end of the rope, the end of the rope is returned. If the point is out of bounds, -1 is returned.
+func (r *Rope) NextWordBoundary(point int) int {
+	return r.nextBoundary(point, r.wordStart, r.wordBoundaries)
+}
+
+// PrevWordBoundary returns the point of the last word boundary before the given point.
+// At the start of the rope, 0 is returned. If the point is out of bounds, -1 is returned.
+func (r *Rope) PrevWordBoundary(point int) int {
+	return r.prevBoundary(point, r.wordStart, r.wordBoundaries)
+}
+
+// SentenceAt returns the bounds of the sentence which the rune at the given point is in. At the end of the rope,
+// the last sentence is returned. If the point is out of bounds, -1, -1 is returned.
+//
+// Sentences are found with the sentence boundary rules of UAX #29. A sentence includes the spaces after it.
+func (r *Rope) SentenceAt(point int) (start, end int) {
+	return r.segmentAt(point, r.sentenceStart, r.sentenceBoundaries)
+}
+
+// NextSentenceBoundary returns the point of the first sentence boundary after the given point.
+// At the end of the rope, the end of the rope is returned. If the point is out of bounds, -1 is returned.
+func (r *Rope) NextSentenceBoundary(point int) int {
+	return r.nextBoundary(point, r.sentenceNext, r.sentenceBoundaries)
+}
+
+// PrevSentenceBoundary returns the point of the last sentence boundary before the given point.
+// At the start of the rope, 0 is returned. If the point is out of bounds, -1 is returned.
+func (r *Rope) PrevSentenceBoundary(point int) int {
+	return r.prevBoundary(point, r.sentenceStart, r.sentenceBoundaries)
+}
+
+// The boundaries of a kind of segment are found with two functions:
+//	start returns a boundary at or before the given point, from which walk finds the right boundaries.
+//	walk calls fn with every boundary after the given point in order, ending with the end of the rope, until fn returns false.
+
+func (r *Rope) segmentAt(point int, start func(int) int, walk func(int, func(int) bool)) (retStart, retEnd int) {
+	if point < 0 || point > r.runes {
+		return -1, -1
+	}
+	if r.runes == 0 {
+		return 0, 0
+	}
+	point = min(point, r.runes-1)
+	retStart, retEnd = start(point), r.runes
+	walk(retStart, func(b int) bool {
+		if b > point {
+			retEnd = b
+			return false
+		}
+		retStart = b
+		return true
+	})
+	return retStart, retEnd
+}
+
+func (r *Rope) nextBoundary(point int, start func(int) int, walk func(int, func(int) bool)) int {
+	if point < 0 || point > r.runes {
+		return -1
+	}
+	retVal := r.runes
+	walk(start(point), func(b int) bool {
+		if b > point {
+			retVal = b
+			return false
+		}
+		return true
+	})
+	return retVal
+}
+
+func (r *Rope) prevBoundary(point int, start func(int) int, walk func(int, func(int) bool)) int {
+	if point < 0 || point > r.runes {
+		return -1
+	}
+	if point == 0 {
+		return 0
+	}
+	retVal := start(point - 1)
+	walk(retVal, func(b int) bool {
+		if b >= point {
+			return false
+		}
+		retVal = b
+		return true
+	})
+	return retVal
+}
+
+/* WORDS */
+
+// wb is the Word_Break property of a rune, as defined in UAX #29.
+type wb byte
+
+const (
+	wbNone wb = iota // the start or end of the text
+	wbOther
+	wbCR
+	wbLF
+	wbNewline
+	wbExtend
+	wbZWJ
+	wbRI
+	wbFormat
+	wbKatakana
+	wbHebrewLetter
+	wbALetter
+	wbSingleQuote
+	wbDoubleQuote
+	wbMidNumLet
+	wbMidLetter
+	wbMidNum
+	wbNumeric
+	wbExtendNumLet
+	wbWSegSpace
+)
+
+// wordClass returns the Word_Break property of a rune.
+func wordClass(r rune) wb {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		return wbALetter
+	case r >= '0' && r <= '9':
+		return wbNumeric
+	case r == ' ', r == 0x1680, r >= 0x2000 && r <= 0x2006, r >= 0x2008 && r <= 0x200a, r == 0x205f, r == 0x3000:
+		return wbWSegSpace
+	case r == '\r':
+		return wbCR
+	case r == '\n':
+		return wbLF
+	case r == 0x0b, r == 0x0c, r == 0x85, r == 0x2028, r == 0x2029:
+		return wbNewline
+	case r == '\'':
+		return wbSingleQuote
+	case r == '"':
+		return wbDoubleQuote
+	case r == 0x200d:
+		return wbZWJ
+	case r == '_', r == 0x202f:
+		return wbExtendNumLet
+	case unicode.Is(wordMidNumLet, r):
+		return wbMidNumLet
+	case unicode.Is(wordMidLetter, r):
+		return wbMidLetter
+	case unicode.Is(wordMidNum, r):
+		return wbMidNum
+	case r < 0x80:
+		return wbOther
+	case r >= 0x1f1e6 && r <= 0x1f1ff:
+		return wbRI
+	case r >= 0x1f3fb && r <= 0x1f3ff: // emoji modifiers
+		return wbExtend
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Other_Grapheme_Extend):
+		return wbExtend
+	case r != 0x200b && unicode.Is(unicode.Cf, r):
+		return wbFormat
+	case unicode.Is(unicode.Katakana, r), r >= 0x3031 && r <= 0x3035, r == 0x309b, r == 0x309c, r == 0x30a0, r == 0x30fc, r == 0xff70:
+		return wbKatakana
+	case unicode.Is(unicode.Hebrew, r) && unicode.Is(unicode.Lo, r):
+		return wbHebrewLetter
+	case unicode.Is(unicode.Nd, r):
+		return wbNumeric
+	case unicode.Is(unicode.Pc, r):
+		return wbExtendNumLet
+	case unicode.In(r, unicode.L, unicode.Nl, unicode.Other_Alphabetic) && !unicode.In(r, notALetter...):
+		return wbALetter
+	}
+	return wbOther
+}
+
+// isAHLetter reports whether the class is ALetter or Hebrew_Letter.
+func (c wb) isAHLetter() bool { return c == wbALetter || c == wbHebrewLetter }
+
+// isMidNumLetQ reports whether the class is MidNumLet or Single_Quote.
+func (c wb) isMidNumLetQ() bool { return c == wbMidNumLet || c == wbSingleQuote }
+
+// isNewline reports whether the class is CR, LF or Newline.
+func (c wb) isNewline() bool { return c == wbCR || c == wbLF || c == wbNewline }
+
+// isIgnored reports whether the class is one of those that are ignored by WB4.
+func (c wb) isIgnored() bool { return c == wbExtend || c == wbFormat || c == wbZWJ }
+
+// wordStart returns the last point at or before the given point that is certainly a word boundary, and that a
+// word breaker starting afresh finds the same boundaries from. This is the case when the rune before the point is a newline,
+// or is a rune that no rule looks behind at, and the rune at the point is not ignored.
+func (r *Rope) wordStart(point int) int {
+	if point <= 0 {
+		return 0
+	}
+	next := wbNone
+	if point < r.runes {
+		next = wordClass(r.Index(point))
+	}
+
+	s := NewReverseScanner(r, point)
+	for ; point > 0; point-- {
+		char, _, err := s.ReadRune()
+		if err != nil {
+			break
+		}
+		c := wordClass(char)
+		switch {
+		case next == wbNone:
+			return point
+		case c == wbCR && next == wbLF:
+		case c.isNewline() || next.isNewline():
+			return point
+		case next.isIgnored():
+		case c == wbOther:
+			return point
+		case c == wbWSegSpace && next != wbWSegSpace:
+			return point
+		}
+		next = c
+	}
+	return 0
+}
+
+// wordBoundaries calls fn with the point of every word boundary after the given point, in order, until fn returns false.
+// The point must be one that wordStart returns.
+func (r *Rope) wordBoundaries(from int, fn func(point int) bool) {
+	s := NewScannerAt(r, from)
+	var raw, prev, prevprev wb // the class of the rune before, and of the two runes before that are not ignored
+	var ri bool                // whether the runes before are an odd number of regional indicators
+	point := from
+	for ; ; point++ {
+		char, _, err := s.ReadRune()
+		if err != nil {
+			break
+		}
+		c := wordClass(char)
+
+		var boundary, ignore bool
+		switch {
+		case point == from: // WB1
+		case raw == wbCR && c == wbLF: // WB3
+		case raw.isNewline() || c.isNewline(): // WB3a, WB3b
+			boundary = true
+		case raw == wbZWJ && unicode.Is(extendedPictographic, char): // WB3c
+		case raw == wbWSegSpace && c == wbWSegSpace: // WB3d
+		case c.isIgnored(): // WB4
+			ignore = true
+		default:
+			var next wb
+			if needsLookahead(prev, c) {
+				next = peekWord(*s)
+			}
+			boundary = wordBreak(prevprev, prev, c, next, ri)
+		}
+
+		raw = c
+		if boundary && !fn(point) {
+			return
+		}
+		if ignore {
+			continue
+		}
+		ri = c == wbRI && !(prev == wbRI && ri)
+		prevprev, prev = prev, c
+	}
+	if point > from {
+		fn(point) // WB2
+	}
+}
+
+// needsLookahead reports whether the boundary between two runes depends on the rune after them.
+func needsLookahead(prev, c wb) bool {
+	return prev.isAHLetter() && (c == wbMidLetter || c.isMidNumLetQ()) ||
+		prev == wbHebrewLetter && c == wbDoubleQuote ||
+		prev == wbNumeric && (c == wbMidNum || c.isMidNumLetQ())
+}
+
+// peekWord returns the class of the next rune that is not ignored.
+func peekWord(s Scanner) wb {
+	for {
+		char, _, err := s.ReadRune()
+		if err != nil {
+			return wbNone
+		}
+		if c := wordClass(char); !c.isIgnored() {
+			return c
+		}
+	}
+}
+
+// wordBreak applies the rules of UAX #29 from WB5 onwards. It reports whether there is a boundary between the runes of class prev and c,
+// given the classes of the runes before and after them which are not ignored.
+func wordBreak(prevprev, prev, c, next wb, ri bool) bool {
+	switch {
+	case prev.isAHLetter() && c.isAHLetter(): // WB5
+	case prev.isAHLetter() && (c == wbMidLetter || c.isMidNumLetQ()) && next.isAHLetter(): // WB6
+	case prevprev.isAHLetter() && (prev == wbMidLetter || prev.isMidNumLetQ()) && c.isAHLetter(): // WB7
+	case prev == wbHebrewLetter && c == wbSingleQuote: // WB7a
+	case prev == wbHebrewLetter && c == wbDoubleQuote && next == wbHebrewLetter: // WB7b
+	case prevprev == wbHebrewLetter && prev == wbDoubleQuote && c == wbHebrewLetter: // WB7c
+	case prev == wbNumeric && c == wbNumeric: // WB8
+	case prev.isAHLetter() && c == wbNumeric: // WB9
+	case prev == wbNumeric && c.isAHLetter(): // WB10
+	case prevprev == wbNumeric && (prev == wbMidNum || prev.isMidNumLetQ()) && c == wbNumeric: // WB11
+	case prev == wbNumeric && (c == wbMidNum || c.isMidNumLetQ()) && next == wbNumeric: // WB12
+	case prev == wbKatakana && c == wbKatakana: // WB13
+	case (prev.isAHLetter() || prev == wbNumeric || prev == wbKatakana || prev == wbExtendNumLet) && c == wbExtendNumLet: // WB13a
+	case prev == wbExtendNumLet && (c.isAHLetter() || c == wbNumeric || c == wbKatakana): // WB13b
+	case prev == wbRI && c == wbRI && ri: // WB15, WB16
+	default: // WB999
+		return true
+	}
+	return false
+}
+
+// notALetter holds the scripts whose letters are not ALetter, as they are not separated by spaces.
+var notALetter = []*unicode.RangeTable{
+	unicode.Ideographic,
+	unicode.Hiragana,
+	unicode.Thai,
+	unicode.Lao,
+	unicode.Myanmar,
+	unicode.Khmer,
+	unicode.Tai_Le,
+	unicode.New_Tai_Lue,
+	unicode.Tai_Tham,
+	unicode.Tai_Viet,
+}
+
+var wordMidNumLet = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x002e, Hi: 0x002e, Stride: 1},
+		{Lo: 0x2018, Hi: 0x2019, Stride: 1},
+		{Lo: 0x2024, Hi: 0x2024, Stride: 1},
+		{Lo: 0xfe52, Hi: 0xfe52, Stride: 1},
+		{Lo: 0xff07, Hi: 0xff07, Stride: 1},
+		{Lo: 0xff0e, Hi: 0xff0e, Stride: 1},
+	},
+	LatinOffset: 1,
+}
+
+var wordMidLetter = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x003a, Hi: 0x003a, Stride: 1},
+		{Lo: 0x00b7, Hi: 0x00b7, Stride: 1},
+		{Lo: 0x0387, Hi: 0x0387, Stride: 1},
+		{Lo: 0x055f, Hi: 0x055f, Stride: 1},
+		{Lo: 0x05f4, Hi: 0x05f4, Stride: 1},
+		{Lo: 0x2027, Hi: 0x2027, Stride: 1},
+		{Lo: 0xfe13, Hi: 0xfe13, Stride: 1},
+		{Lo: 0xfe55, Hi: 0xfe55, Stride: 1},
+		{Lo: 0xff1a, Hi: 0xff1a, Stride: 1},
+	},
+	LatinOffset: 2,
+}
+
+var wordMidNum = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x002c, Hi: 0x002c, Stride: 1},
+		{Lo: 0x003b, Hi: 0x003b, Stride: 1},
+		{Lo: 0x037e, Hi: 0x037e, Stride: 1},
+		{Lo: 0x0589, Hi: 0x0589, Stride: 1},
+		{Lo: 0x060c, Hi: 0x060d, Stride: 1},
+		{Lo: 0x066c, Hi: 0x066c, Stride: 1},
+		{Lo: 0x07f8, Hi: 0x07f8, Stride: 1},
+		{Lo: 0x2044, Hi: 0x2044, Stride: 1},
+		{Lo: 0xfe10, Hi: 0xfe10, Stride: 1},
+		{Lo: 0xfe14, Hi: 0xfe14, Stride: 1},
+		{Lo: 0xfe50, Hi: 0xfe50, Stride: 1},
+		{Lo: 0xfe54, Hi: 0xfe54, Stride: 1},
+		{Lo: 0xff0c, Hi: 0xff0c, Stride: 1},
+		{Lo: 0xff1b, Hi: 0xff1b, Stride: 1},
+	},
+	LatinOffset: 2,
+}
+
+/* SENTENCES */
+
+// sb is the Sentence_Break property of a rune, as defined in UAX #29.
+type sb byte
+
+const (
+	sbOther sb = iota
+	sbCR
+	sbLF
+	sbSep
+	sbExtend
+	sbFormat
+	sbSp
+	sbLower
+	sbUpper
+	sbOLetter
+	sbNumeric
+	sbATerm
+	sbSTerm
+	sbSContinue
+	sbClose
+)
+
+// sentenceClass returns the Sentence_Break property of a rune.
+func sentenceClass(r rune) sb {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return sbLower
+	case r >= 'A' && r <= 'Z':
+		return sbUpper
+	case r >= '0' && r <= '9':
+		return sbNumeric
+	case r == '\r':
+		return sbCR
+	case r == '\n':
+		return sbLF
+	case r == 0x85 || r == 0x2028 || r == 0x2029:
+		return sbSep
+	case r == '.' || r == 0x2024 || r == 0xfe52 || r == 0xff0e:
+		return sbATerm
+	case unicode.Is(unicode.White_Space, r):
+		return sbSp
+	case r == 0x200d || r >= 0x1f3fb && r <= 0x1f3ff:
+		return sbExtend
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Other_Grapheme_Extend):
+		return sbExtend
+	case r != 0x200b && unicode.Is(unicode.Cf, r):
+		return sbFormat
+	case unicode.Is(unicode.Sentence_Terminal, r):
+		return sbSTerm
+	case unicode.Is(sentenceSContinue, r):
+		return sbSContinue
+	case unicode.In(r, unicode.Ps, unicode.Pe, unicode.Pi, unicode.Pf, unicode.Quotation_Mark):
+		return sbClose
+	case unicode.Is(unicode.Nd, r):
+		return sbNumeric
+	case unicode.In(r, unicode.Lu, unicode.Lt, unicode.Other_Uppercase):
+		return sbUpper
+	case unicode.In(r, unicode.Ll, unicode.Other_Lowercase):
+		return sbLower
+	case unicode.In(r, unicode.L, unicode.Nl, unicode.Other_Alphabetic):
+		return sbOLetter
+	}
+	return sbOther
+}
+
+// isParaSep reports whether the class is Sep, CR or LF.
+func (c sb) isParaSep() bool { return c == sbSep || c == sbCR || c == sbLF }
+
+// sentenceResume returns the point of the last rune at or before the given point that is not part of SATerm Close* Sp* ParaSep?,
+// or the start of the paragraph if there is none, in which case start is true. A walk from the returned point is in the same state
+// as a walk from the start of the text, so it finds the boundaries after the point.
+func (r *Rope) sentenceResume(point int) (retVal int, start bool) {
+	retVal = min(point+1, r.runes)
+	s := NewReverseScanner(r, retVal)
+	for {
+		char, _, err := s.ReadRune()
+		if err != nil {
+			return 0, true // SB1
+		}
+		retVal--
+		switch c := sentenceClass(char); {
+		case c.isParaSep():
+			// SB4, except between a CR and a LF
+			return retVal + 1, !(c == sbCR && r.Index(retVal+1) == '\n')
+		case c == sbATerm || c == sbSTerm || c == sbClose || c == sbSp || c == sbExtend || c == sbFormat:
+		default:
+			return retVal, false
+		}
+	}
+}
+
+// sentenceStart returns a sentence boundary at or before the given point. As boundaries only follow a paragraph separator
+// or SATerm Close* Sp*, the runes before the point are walked from a sentenceResume some way back, which is moved back further
+// each time there is no boundary, up to the start of the line.
+func (r *Rope) sentenceStart(point int) int {
+	line, _ := r.PointToLineCol(point)
+	lineStart := r.LineStart(line)
+	for lookback := 64; ; lookback *= 2 {
+		from, start := lineStart, true
+		if point-lookback > lineStart {
+			from, start = r.sentenceResume(point - lookback)
+		}
+		retVal := -1
+		if start {
+			retVal = from
+		}
+		r.sentenceWalk(from, point, func(b int) bool {
+			retVal = b
+			return true
+		})
+		if retVal >= 0 {
+			return retVal
+		}
+	}
+}
+
+// sentenceNext returns the point from which sentenceBoundaries finds the boundaries after the given point.
+func (r *Rope) sentenceNext(point int) int {
+	// if the rune at the point is a paragraph separator, the walk starts at it, so that the boundary after it is found.
+	retVal, _ := r.sentenceResume(point)
+	return min(retVal, point)
+}
+
+const (
+	termOnly  = iota // SATerm
+	termClose        // SATerm Close+
+	termSp           // SATerm Close* Sp+
+)
+
+// sentenceBoundaries calls fn with the point of every sentence boundary after the given point, in order, until fn returns false.
+// The point must be a sentence boundary, or one that sentenceResume returns.
+func (r *Rope) sentenceBoundaries(from int, fn func(point int) bool) {
+	r.sentenceWalk(from, r.runes, fn)
+}
+
+// sentenceWalk is like sentenceBoundaries, except that it stops after the boundaries at or before the point to.
+func (r *Rope) sentenceWalk(from, to int, fn func(point int) bool) {
+	s := NewScannerAt(r, from)
+	var raw, prev sb    // the class of the rune before, and of the rune before that is not ignored
+	var term sb         // sbATerm or sbSTerm if the runes before are SATerm Close* Sp*
+	var seq int         // how far into SATerm Close* Sp* the runes before are
+	var upperLower bool // whether the rune before the terminator is Upper or Lower
+	point := from
+	for ; point <= to; point++ {
+		char, _, err := s.ReadRune()
+		if err != nil {
+			break
+		}
+		c := sentenceClass(char)
+
+		var boundary, ignore bool
+		switch {
+		case point == from: // SB1
+		case raw == sbCR && c == sbLF: // SB3
+		case raw.isParaSep(): // SB4
+			boundary = true
+		case c == sbExtend || c == sbFormat: // SB5
+			ignore = true
+		case term == sbOther: // SB998
+		case term == sbATerm && seq == termOnly && c == sbNumeric: // SB6
+		case term == sbATerm && seq == termOnly && upperLower && c == sbUpper: // SB7
+		case term == sbATerm && lowerFollows(c, *s): // SB8
+		case c == sbSContinue || c == sbATerm || c == sbSTerm: // SB8a
+		case seq != termSp && c == sbClose: // SB9
+		case c == sbSp || c.isParaSep(): // SB9, SB10
+		default: // SB11
+			boundary = true
+		}
+
+		raw = c
+		if boundary {
+			if !fn(point) {
+				return
+			}
+			term = sbOther
+		}
+		if ignore {
+			continue
+		}
+		switch {
+		case c == sbATerm || c == sbSTerm:
+			term, seq = c, termOnly
+			upperLower = prev == sbUpper || prev == sbLower
+		case term != sbOther && seq != termSp && c == sbClose:
+			seq = termClose
+		case term != sbOther && c == sbSp:
+			seq = termSp
+		case term != sbOther && c.isParaSep():
+		default:
+			term = sbOther
+		}
+		prev = c
+	}
+	if point > from && point <= to {
+		fn(point) // SB2
+	}
+}
+
+// lowerFollows reports whether the first rune from the one of the given class onwards that is OLetter, Upper, Lower, a separator
+// or a terminator is Lower, as in SB8.
+func lowerFollows(c sb, s Scanner) bool {
+	for {
+		switch c {
+		case sbLower:
+			return true
+		case sbOLetter, sbUpper, sbCR, sbLF, sbSep, sbATerm, sbSTerm:
+			return false
+		}
+		char, _, err := s.ReadRune()
+		if err != nil {
+			return false
+		}
+		c = sentenceClass(char)
+	}
+}
+
+var sentenceSContinue = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x002c, Hi: 0x002d, Stride: 1},
+		{Lo: 0x003a, Hi: 0x003a, Stride: 1},
+		{Lo: 0x055d, Hi: 0x055d, Stride: 1},
+		{Lo: 0x060c, Hi: 0x060d, Stride: 1},
+		{Lo: 0x07f8, Hi: 0x07f8, Stride: 1},
+		{Lo: 0x1802, Hi: 0x1802, Stride: 1},
+		{Lo: 0x1808, Hi: 0x1808, Stride: 1},
+		{Lo: 0x2013, Hi: 0x2014, Stride: 1},
+		{Lo: 0x3001, Hi: 0x3001, Stride: 1},
+		{Lo: 0xfe10, Hi: 0xfe11, Stride: 1},
+		{Lo: 0xfe13, Hi: 0xfe13, Stride: 1},
+		{Lo: 0xfe31, Hi: 0xfe32, Stride: 1},
+		{Lo: 0xfe50, Hi: 0xfe51, Stride: 1},
+		{Lo: 0xfe55, Hi: 0xfe55, Stride: 1},
+		{Lo: 0xfe58, Hi: 0xfe58, Stride: 1},
+		{Lo: 0xfe63, Hi: 0xfe63, Stride: 1},
+		{Lo: 0xff0c, Hi: 0xff0d, Stride: 1},
+		{Lo: 0xff1a, Hi: 0xff1a, Stride: 1},
+		{Lo: 0xff64, Hi: 0xff64, Stride: 1},
+	},
+	LatinOffset: 2,
+}
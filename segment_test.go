@@ -0,0 +1,175 @@
+package skiprope
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// segments splits the rope into segments with the given boundary function.
+func segments(r *Rope, walk func(int, func(int) bool)) (retVal []string) {
+	start := 0
+	walk(0, func(b int) bool {
+		retVal = append(retVal, r.Substr(start, b))
+		start = b
+		return true
+	})
+	return retVal
+}
+
+func TestRope_Words(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected []string
+	}{
+		{"", nil},
+		{"The quick (“brown”) fox can’t jump 32.3 feet, right?",
+			[]string{"The", " ", "quick", " ", "(", "“", "brown", "”", ")", " ", "fox", " ", "can’t", " ", "jump", " ", "32.3", " ", "feet", ",", " ", "right", "?"}},
+		{"3.14 a.b 1,000 e.g. don't", []string{"3.14", " ", "a.b", " ", "1,000", " ", "e.g", ".", " ", "don't"}},
+		{"a. b: c, 1.a", []string{"a", ".", " ", "b", ":", " ", "c", ",", " ", "1", ".", "a"}},
+		{"你好世界", []string{"你", "好", "世", "界"}},
+		{"カタカナ ひらがな", []string{"カタカナ", " ", "ひ", "ら", "が", "な"}},
+		{"foo_bar42 _x", []string{"foo_bar42", " ", "_x"}},
+		{"étude​", []string{"étude", "​"}},
+		{"a  \t b", []string{"a", "  ", "\t", " ", "b"}},
+		{"a\r\n\nb", []string{"a", "\r\n", "\n", "b"}},
+		{"̈a\n̈b", []string{"̈", "a", "\n", "̈", "b"}},
+		{"👩‍👩‍👧 🇦🇧🇨", []string{"👩‍👩‍👧", " ", "🇦🇧", "🇨"}},
+		{"א\"ב א'", []string{"א\"ב", " ", "א'"}},
+	}
+	for _, wt := range tests {
+		r := New()
+		r.Insert(0, wt.s)
+		assert.Equal(t, wt.expected, segments(r, r.wordBoundaries), "%+q", wt.s)
+	}
+}
+
+func TestRope_Sentences(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected []string
+	}{
+		{"", nil},
+		{"This is a test. This is another! And... what? Yes.", []string{"This is a test. ", "This is another! ", "And... what? ", "Yes."}},
+		{"Mr. Smith went to Washington.", []string{"Mr. ", "Smith went to Washington."}},
+		{"etc. and so on. U.S.A. is big.", []string{"etc. and so on. ", "U.S.A. is big."}},
+		{"3.4 is pi-ish. (Really.) Ok", []string{"3.4 is pi-ish. ", "(Really.) ", "Ok"}},
+		{"He said “Stop.” Then left.", []string{"He said “Stop.” ", "Then left."}},
+		{"Line one\nLine two\r\nThree", []string{"Line one\n", "Line two\r\n", "Three"}},
+		{"Wait. \n\nWhat?", []string{"Wait. \n", "\n", "What?"}},
+		{"你好。世界！", []string{"你好。", "世界！"}},
+	}
+	for _, st := range tests {
+		r := New()
+		r.Insert(0, st.s)
+		assert.Equal(t, st.expected, segments(r, r.sentenceBoundaries), "%+q", st.s)
+	}
+}
+
+// checkBoundaries checks that Next, Prev and At agree with the boundaries found from the start of the rope.
+func checkBoundaries(t *testing.T, r *Rope, walk func(int, func(int) bool), next, prev func(int) int, at func(int) (int, int)) {
+	assert := assert.New(t)
+	boundaries := []int{0}
+	walk(0, func(b int) bool {
+		boundaries = append(boundaries, b)
+		return true
+	})
+	n := r.Runes()
+
+	b := 0 // boundaries[b] <= point < boundaries[b+1]
+	for point := 0; point <= n; point++ {
+		if b+1 < len(boundaries) && boundaries[b+1] <= point {
+			b++
+		}
+		expectedNext := n
+		if b+1 < len(boundaries) {
+			expectedNext = boundaries[b+1]
+		}
+		if !assert.Equal(expectedNext, next(point), "Next(%d)", point) {
+			return
+		}
+
+		expectedPrev := boundaries[b]
+		if expectedPrev == point && b > 0 {
+			expectedPrev = boundaries[b-1]
+		}
+		if !assert.Equal(expectedPrev, prev(point), "Prev(%d)", point) {
+			return
+		}
+
+		start, end := at(point)
+		if point == n && n > 0 {
+			assert.Equal(boundaries[len(boundaries)-2], start, "At(%d)", point)
+			assert.Equal(n, end, "At(%d)", point)
+		} else {
+			assert.Equal(boundaries[b], start, "At(%d)", point)
+			assert.Equal(expectedNext, end, "At(%d)", point)
+		}
+	}
+
+	assert.Equal(-1, next(-1))
+	assert.Equal(-1, prev(n+1))
+	start, end := at(n + 1)
+	assert.Equal(-1, start)
+	assert.Equal(-1, end)
+}
+
+func TestRope_Boundaries(t *testing.T) {
+	alphabet := []string{"a", "B", "1", " ", " ", "\t", "\n", "\r", ".", ",", ":", "'", "\"", "!", "(", ")", "_",
+		"̈", "‍", "­", "👩", "🇦", "你", "カ", "א"}
+	rnd := rand.New(rand.NewSource(1337))
+	var buf []string
+	for i := 0; i < 1500; i++ {
+		buf = append(buf, alphabet[rnd.Intn(len(alphabet))])
+	}
+	// long runs which are not broken
+	buf = append(buf, strings.Repeat("ä", 100), strings.Repeat("🇦", 101), strings.Repeat("  ", 50))
+	r := NewFromBytes([]byte(strings.Join(buf, "")))
+
+	checkBoundaries(t, r, r.wordBoundaries, r.NextWordBoundary, r.PrevWordBoundary, r.WordAt)
+	checkBoundaries(t, r, r.sentenceBoundaries, r.NextSentenceBoundary, r.PrevSentenceBoundary, r.SentenceAt)
+
+	// a single line, which has to be scanned back to the terminators rather than to the start of the line
+	buf = buf[:0]
+	for i := 0; i < 1500; i++ {
+		if char := alphabet[rnd.Intn(len(alphabet))]; char != "\n" && char != "\r" {
+			buf = append(buf, char)
+		}
+		if rnd.Intn(10) == 0 {
+			buf = append(buf, []string{"?", ". ", ".) ", "! A", ". a", ".1"}[rnd.Intn(6)])
+		}
+	}
+	r = NewFromBytes([]byte(strings.Join(buf, "")))
+	checkBoundaries(t, r, r.sentenceBoundaries, r.NextSentenceBoundary, r.PrevSentenceBoundary, r.SentenceAt)
+
+	r = New()
+	checkBoundaries(t, r, r.wordBoundaries, r.NextWordBoundary, r.PrevWordBoundary, r.WordAt)
+}
+
+func TestRope_WordAt(t *testing.T) {
+	assert := assert.New(t)
+	r := New()
+	r.Insert(0, "Hello, wonderful 世界!")
+
+	start, end := r.WordAt(9)
+	assert.Equal("wonderful", r.Substr(start, end))
+	start, end = r.WordAt(7)
+	assert.Equal("wonderful", r.Substr(start, end))
+	start, end = r.WordAt(5)
+	assert.Equal(",", r.Substr(start, end))
+	start, end = r.WordAt(r.Runes())
+	assert.Equal("!", r.Substr(start, end))
+
+	assert.Equal(16, r.NextWordBoundary(7))
+	assert.Equal(7, r.PrevWordBoundary(16))
+	assert.Equal(0, r.PrevWordBoundary(3))
+
+	r = New()
+	r.Insert(0, "One sentence. And another.")
+	start, end = r.SentenceAt(20)
+	assert.Equal("And another.", r.Substr(start, end))
+	assert.Equal(14, r.NextSentenceBoundary(0))
+	assert.Equal(14, r.PrevSentenceBoundary(20))
+}
@@ -0,0 +1,38 @@
+package skiprope
+
+// Snapshot returns a copy of the rope which shares its knots with the rope. Taking a snapshot costs O(height),
+// which makes it cheap to hand a consistent view of the rope over to another goroutine.
+//
+// The knots are copy-on-write: when either rope is modified, the shared knots up to the modified knot are cloned first,
+// so modifying one rope never affects the other. Knots are only linked forwards, so a shared knot cannot be replaced
+// by its clone unless every knot before it is cloned too. This means that the first edit after a snapshot costs O(n),
+// where n is the number of knots up to the edited knot - for an edit at the end of the rope, this is as much as String costs.
+// Each knot is cloned at most once per snapshot, so later edits of the cloned part of the rope cost as much as usual.
+// Concat, Splice and ReadFrom clone the whole rope in the same way if it shares its knots with a snapshot.
+//
+// A snapshot is meant to be read-only. It is safe to read from the snapshot in one goroutine while the original rope
+// is modified in another.
+func (r *Rope) Snapshot() *Rope {
+	retVal := &Rope{
+		Head:       r.Head,
+		size:       r.size,
+		runes:      r.runes,
+		lines:      r.lines,
+		codeUnits:  r.codeUnits,
+		epoch:      nextEpoch(),
+		shared:     true,
+		bias:       r.bias,
+		bucketSize: r.bucketSize,
+	}
+	retVal.Head.data = make([]byte, len(r.Head.data))
+	copy(retVal.Head.data, r.Head.data[:r.Head.used])
+	retVal.Head.nexts = make([]skipknot, MaxHeight)
+	copy(retVal.Head.nexts, r.Head.nexts)
+	retVal.Head.epoch = retVal.epoch
+
+	// the rope moves on to a new epoch, so that the knots which are now shared are cloned before they are modified.
+	r.epoch = nextEpoch()
+	r.Head.epoch = r.epoch
+	r.shared = true
+	return retVal
+}
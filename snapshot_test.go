@@ -0,0 +1,92 @@
+package skiprope
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRope_Snapshot(t *testing.T) {
+	r := New()
+	if err := r.Insert(0, a); err != nil {
+		t.Fatal(err)
+	}
+	snap := r.Snapshot()
+	assert.Equal(t, a, snap.String())
+
+	rnd := rand.New(rand.NewSource(1337))
+	expected := []rune(a)
+	for i := 0; i < 200; i++ {
+		at := rnd.Intn(len(expected) + 1)
+		if rnd.Intn(2) == 0 {
+			n := rnd.Intn(200)
+			if err := r.EraseAt(at, n); err != nil {
+				t.Fatal(err)
+			}
+			expected = append(expected[:at:at], expected[min(at+n, len(expected)):]...)
+		} else {
+			if err := r.Insert(at, "INSERTED 你好"); err != nil {
+				t.Fatal(err)
+			}
+			expected = append(expected[:at:at], append([]rune("INSERTED 你好"), expected[at:]...)...)
+		}
+
+		// take more snapshots along the way
+		if i%50 == 0 {
+			s := r.Snapshot()
+			defer func(want string) { assert.Equal(t, want, s.String()) }(string(expected))
+		}
+	}
+	assert.Equal(t, string(expected), r.String())
+	assert.Equal(t, a, snap.String())
+	assert.Equal(t, len(a), snap.Size())
+	validRope(t, r)
+	validRope(t, snap)
+
+	// modifying the snapshot does not modify the original
+	if err := snap.EraseAt(0, 100); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string([]rune(a)[100:]), snap.String())
+	assert.Equal(t, string(expected), r.String())
+	validRope(t, snap)
+
+	// byte addressed modifications
+	snap2 := r.Snapshot()
+	if err := r.InsertAtByte(300, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.EraseBytes(100, 50); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(expected), snap2.String())
+	validRope(t, r)
+}
+
+func TestRope_SnapshotConcurrent(t *testing.T) {
+	r := New()
+	if err := r.Insert(0, a); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		snap := r.Snapshot()
+		want := r.String()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, want, snap.String())
+		}()
+		if err := r.Insert(i*100, "Hello World"); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.EraseAt(i*50, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	validRope(t, r)
+}
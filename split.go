@@ -0,0 +1,162 @@
+package skiprope
+
+// Split splits the rope at the point. The runes before the point stay in the rope, while the runes after the point
+// are moved to a new rope. The rope and the new rope are returned, in that order.
+//
+// The knots after the point are relinked into the new rope rather than copied, so only the bytes of the knot holding the point are copied.
+// If the rope shares its knots with a snapshot, all the shared knots up to the point are cloned first, as they are by an edit (see Snapshot).
+func (r *Rope) Split(point int) (*Rope, *Rope) {
+	point = clamp(point, 0, r.runes)
+	c := r.beforeErase(point, r.runes-point)
+	retVal := r.split(point)
+	r.afterErase(c)
+	return r, retVal
+}
+
+// Concat moves all the runes of the other rope to the end of the rope, leaving the other rope empty. The cost is that of Splice.
+func (r *Rope) Concat(other *Rope) { r.Splice(r.runes, other) }
+
+// Splice moves all the runes of the other rope into the rope at the given point, leaving the other rope empty.
+//
+// The knots of the other rope are relinked into the rope rather than copied. This only costs O(log n) if neither rope shares
+// its knots with a snapshot. Otherwise, all the shared knots of the rope are cloned first (see Snapshot), and if the other rope
+// shares its knots, every knot of the rope is moved to a new epoch, so that they can be told apart from the shared knots.
+// Either costs O(n).
+func (r *Rope) Splice(point int, other *Rope) {
+	if other == r {
+		other = r.Snapshot()
+	}
+	point = clamp(point, 0, r.runes)
+	runes := other.runes
+	if runes == 0 {
+		return
+	}
+
+	var data []byte
+	if r.history != nil || len(r.observers) > 0 {
+		data = other.SubstrBytes(0, runes)
+	}
+	c := other.beforeErase(0, runes)
+
+	tail := r.split(point)
+	r.concat(other)
+	r.concat(tail)
+
+	other.afterErase(c)
+	r.afterInsert(point, runes, data)
+}
+
+// split moves the runes after the point, which has to be within the bounds of the rope, to a new rope, which is returned.
+// The edit hooks are not called.
+func (r *Rope) split(point int) *Rope {
+	s := skiplist{r: r}
+	s.own(point)
+	k, offset, _ := s.at(point)
+
+	// the knots after the point may be shared, as they may have come from a snapshot.
+	// The new rope starts at a new epoch, so none of its knots are considered to belong to it yet.
+	retVal := r.newSibling()
+	retVal.shared = r.shared
+
+	// the data after the point goes into the head of the new rope. The knot may be larger than the rope's buckets if it came from another rope.
+	if k.used-offset > len(retVal.Head.data) {
+		retVal.Head.data = make([]byte, k.used-offset)
+	}
+	retVal.Head.used = copy(retVal.Head.data, k.data[offset:k.used])
+	retVal.Head.height = r.Head.height
+	for i := 0; i < r.Head.height; i++ {
+		prev := s.s[i]
+		next := prev.knot.nexts[i]
+		retVal.Head.nexts[i] = skipknot{
+			knot:         next.knot,
+			skipped:      next.skipped - prev.skipped,
+			skippedRunes: next.skippedRunes - prev.skippedRunes,
+			skippedLines: next.skippedLines - prev.skippedLines,
+			skippedUTF16: next.skippedUTF16 - prev.skippedUTF16,
+		}
+		prev.knot.nexts[i] = skipknot{nil, prev.skipped, prev.skippedRunes, prev.skippedLines, prev.skippedUTF16}
+	}
+	k.used = offset
+
+	// the topmost level of the search starts at the head, so it holds the counts before the point
+	top := s.s[r.Head.height-1]
+	retVal.size = r.size - top.skipped
+	retVal.runes = r.runes - top.skippedRunes
+	retVal.lines = r.lines - top.skippedLines
+	retVal.codeUnits = r.codeUnits - top.skippedUTF16
+	r.size = top.skipped
+	r.runes = top.skippedRunes
+	r.lines = top.skippedLines
+	r.codeUnits = top.skippedUTF16
+
+	// every knot that is left was owned above
+	r.shared = false
+	return retVal
+}
+
+// concat moves the knots of the other rope to the end of the rope, leaving the other rope empty. The edit hooks are not called.
+func (r *Rope) concat(other *Rope) {
+	if other.size == 0 {
+		return
+	}
+
+	s := skiplist{r: r}
+	s.own(r.runes)
+	s.at(r.runes)
+
+	if other.shared {
+		// the knots of the other rope may be shared with a snapshot. The knots of the rope are moved to a new epoch,
+		// so that they can still be told apart from the shared knots, which have to be cloned before they are modified.
+		r.epoch = nextEpoch()
+		for k := &r.Head; k != nil; k = k.nexts[0].knot {
+			k.epoch = r.epoch
+		}
+		r.shared = true
+	}
+
+	// the head of the other rope is not a knot that can be relinked, so its data is moved into a new knot.
+	h := other.Head
+	var height int
+	if h.used > 0 {
+		height = max(1, h.height-1)
+	}
+	for r.Head.height < max(h.height, height+1) {
+		r.Head.nexts[r.Head.height] = r.Head.nexts[r.Head.height-1]
+		s.s[r.Head.height] = s.s[r.Head.height-1]
+		r.Head.height++
+	}
+
+	var k *knot
+	if height > 0 {
+		k = newKnot(height, r.epoch, 0)
+		k.data = h.data
+		k.used = h.used
+		copy(k.nexts, h.nexts[:height])
+	}
+
+	for i := 0; i < r.Head.height; i++ {
+		var next skipknot
+		switch {
+		case i < height:
+			next = skipknot{knot: k}
+		case i < h.height:
+			next = h.nexts[i]
+		default:
+			next = skipknot{nil, other.size, other.runes, other.lines, other.codeUnits}
+		}
+		last := s.s[i]
+		last.knot.nexts[i] = skipknot{
+			knot:         next.knot,
+			skipped:      last.skipped + next.skipped,
+			skippedRunes: last.skippedRunes + next.skippedRunes,
+			skippedLines: last.skippedLines + next.skippedLines,
+			skippedUTF16: last.skippedUTF16 + next.skippedUTF16,
+		}
+	}
+
+	r.size += other.size
+	r.runes += other.runes
+	r.lines += other.lines
+	r.codeUnits += other.codeUnits
+	Init(other)
+}
@@ -0,0 +1,194 @@
+package skiprope
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const splitText = "Hello World! 你好世界!\nこんにちは世界! 𝄞 is a surrogate pair in UTF-16.\n"
+
+func TestRope_Split(t *testing.T) {
+	assert := assert.New(t)
+	str := strings.Repeat(splitText, 20)
+	runes := []rune(str)
+
+	for _, point := range []int{0, 1, 5, 13, 64, 100, 500, len(runes) - 1, len(runes)} {
+		r := New()
+		if err := r.Insert(0, str); err != nil {
+			t.Fatal(err)
+		}
+		left, right := r.Split(point)
+		assert.True(left == r)
+		validRope(t, left)
+		validRope(t, right)
+		assert.Equal(string(runes[:point]), left.String(), "Split(%d)", point)
+		assert.Equal(string(runes[point:]), right.String(), "Split(%d)", point)
+		assert.Equal(strings.Count(string(runes[point:]), "\n")+1, right.LineCount())
+
+		// both ropes can still be edited
+		if err := left.Insert(left.Runes(), "ABC"); err != nil {
+			t.Fatal(err)
+		}
+		if err := right.Insert(0, "DEF"); err != nil {
+			t.Fatal(err)
+		}
+		if err := right.EraseAt(right.Runes()/2, 10); err != nil {
+			t.Fatal(err)
+		}
+		validRope(t, left)
+		validRope(t, right)
+		assert.Equal(string(runes[:point])+"ABC", left.String())
+	}
+
+	// out of bounds points are clamped
+	r := New()
+	r.Insert(0, "Hello")
+	left, right := r.Split(10)
+	assert.Equal("Hello", left.String())
+	assert.Equal("", right.String())
+}
+
+func TestRope_Concat(t *testing.T) {
+	assert := assert.New(t)
+
+	a := NewFromBytes([]byte(strings.Repeat(splitText, 10)))
+	b := New()
+	if err := b.Insert(0, strings.Repeat("Goodbye 世界\n", 30)); err != nil {
+		t.Fatal(err)
+	}
+	expected := a.String() + b.String()
+
+	a.Concat(b)
+	validRope(t, a)
+	validRope(t, b)
+	assert.Equal(expected, a.String())
+	assert.Equal("", b.String())
+	assert.Equal(strings.Count(expected, "\n")+1, a.LineCount())
+
+	// concatenating a rope to itself doubles it
+	a.Concat(a)
+	validRope(t, a)
+	assert.Equal(expected+expected, a.String())
+
+	// concatenating to an empty rope
+	c := New()
+	c.Concat(a)
+	validRope(t, c)
+	assert.Equal(expected+expected, c.String())
+	assert.Equal(0, a.Runes())
+}
+
+func TestRope_Splice(t *testing.T) {
+	assert := assert.New(t)
+	rnd := rand.New(rand.NewSource(1337))
+
+	r := New()
+	model := []rune(splitText)
+	if err := r.Insert(0, splitText); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		var other *Rope
+		src := []rune(strings.Repeat(splitText, rnd.Intn(3)))
+		str := string(src[:rnd.Intn(len(src)+1)])
+		if rnd.Intn(2) == 0 {
+			other = NewFromBytes([]byte(str))
+		} else {
+			other = New()
+			other.Insert(0, str)
+		}
+		point := rnd.Intn(len(model) + 1)
+		r.Splice(point, other)
+		model = append(model[:point], append([]rune(str), model[point:]...)...)
+
+		if rnd.Intn(4) == 0 {
+			point = rnd.Intn(len(model) + 1)
+			var right *Rope
+			_, right = r.Split(point)
+			assert.Equal(string(model[point:]), right.String())
+			r.Concat(right)
+		}
+	}
+	validRope(t, r)
+	assert.Equal(string(model), r.String())
+}
+
+func TestRope_Splice_Snapshot(t *testing.T) {
+	assert := assert.New(t)
+
+	a := NewFromBytes([]byte(strings.Repeat(splitText, 10)))
+	b := NewFromBytes([]byte(strings.Repeat("Goodbye 世界\n", 30)))
+	aStr, bStr := a.String(), b.String()
+	snapA := a.Snapshot()
+	snapB := b.Snapshot()
+
+	a.Splice(100, b)
+	if err := a.Insert(110, "edited"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.EraseAt(90, 30); err != nil {
+		t.Fatal(err)
+	}
+	validRope(t, a)
+
+	runes := []rune(aStr)
+	expected := []rune(string(runes[:100]) + bStr + string(runes[100:]))
+	expected = append(expected[:110], append([]rune("edited"), expected[110:]...)...)
+	expected = append(expected[:90], expected[120:]...)
+	assert.Equal(string(expected), a.String())
+
+	// the snapshots are not affected
+	assert.Equal(aStr, snapA.String())
+	assert.Equal(bStr, snapB.String())
+	validRope(t, snapA)
+	validRope(t, snapB)
+
+	// neither is a rope split off from a snapshotted rope
+	snap := a.Snapshot()
+	_, right := a.Split(50)
+	if err := right.Insert(10, "right"); err != nil {
+		t.Fatal(err)
+	}
+	validRope(t, right)
+	assert.Equal(string(expected), snap.String())
+}
+
+func TestRope_Splice_Hooks(t *testing.T) {
+	assert := assert.New(t)
+
+	r := New()
+	r.Insert(0, "Hello World")
+	h := NewHistory(r)
+	m := r.NewMark(6, LeftGravity)
+	var changes []Change
+	r.Subscribe(func(c Change) { changes = append(changes, c) })
+
+	other := New()
+	other.Insert(0, "Big ")
+	otherMark := other.NewMark(2, RightGravity)
+	r.Splice(6, other)
+	assert.Equal("Hello Big World", r.String())
+	assert.Equal(6, m.Pos())
+	assert.Equal(0, otherMark.Pos())
+	if assert.Len(changes, 1) {
+		assert.Equal("Big ", string(changes[0].Inserted))
+		assert.Equal(6, changes[0].Point)
+	}
+
+	if err := h.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("Hello World", r.String())
+
+	_, right := r.Split(5)
+	assert.Equal("Hello", r.String())
+	assert.Equal(" World", right.String())
+	assert.Equal(5, m.Pos())
+	if err := h.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("Hello World", r.String())
+}
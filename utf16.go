@@ -0,0 +1,65 @@
+package skiprope
+
+import (
+	"unicode/utf8"
+)
+
+// UTF16Len returns the length of the rope in UTF-16 code units.
+func (r *Rope) UTF16Len() int { return r.codeUnits }
+
+// UTF16Offset returns the offset in UTF-16 code units of the rune at the given point.
+// If the point is out of bounds, -1 is returned.
+func (r *Rope) UTF16Offset(point int) int {
+	if point < 0 || point > r.runes {
+		return -1
+	}
+
+	s := skiplist{r: r}
+	if _, _, _, err := s.find(point); err != nil {
+		return -1
+	}
+	// the topmost level of the search always starts at the head, so it holds the number of code units before the point.
+	return s.s[r.Head.height-1].skippedUTF16
+}
+
+// PointFromUTF16 returns the point of the rune at the given offset in UTF-16 code units.
+// If the offset falls in the middle of a surrogate pair, the point of the rune encoded by the pair is returned.
+// If the offset is out of bounds, -1 is returned.
+func (r *Rope) PointFromUTF16(off int) int {
+	if off < 0 || off > r.codeUnits {
+		return -1
+	}
+
+	s := skiplist{r: r}
+	k, n, skippedRunes, err := s.findUTF16(off)
+	if err != nil {
+		return -1
+	}
+
+	for i := 0; i < k.used; {
+		char, size := utf8.DecodeRune(k.data[i:k.used])
+		width := utf16Len(char)
+		if width > n {
+			break
+		}
+		n -= width
+		i += size
+		skippedRunes++
+	}
+	return skippedRunes
+}
+
+// InsertUTF16 inserts the string at the given offset in UTF-16 code units. The offset is clamped to the bounds of the rope.
+func (r *Rope) InsertUTF16(off int, str string) error {
+	point := r.PointFromUTF16(clamp(off, 0, r.codeUnits))
+	return r.InsertBytes(point, []byte(str))
+}
+
+// EraseAtUTF16 erases n UTF-16 code units starting from the given offset in UTF-16 code units.
+// The offset is clamped to the bounds of the rope, and n to the code units after the offset.
+func (r *Rope) EraseAtUTF16(off, n int) error {
+	off = clamp(off, 0, r.codeUnits)
+	point := r.PointFromUTF16(off)
+	end := r.PointFromUTF16(clamp(off+n, off, r.codeUnits))
+	return r.EraseAt(point, end-point)
+}
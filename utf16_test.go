@@ -0,0 +1,69 @@
+package skiprope
+
+import (
+	"math/rand"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRope_UTF16(t *testing.T) {
+	s := "Hello 😀 world. 你好世界 is a longer sentence with 🎉 emoji spanning 𝄞 several knots of the rope."
+	r := New()
+	if err := r.Insert(0, s); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(utf16.Encode([]rune(s))), r.UTF16Len())
+
+	var off int
+	for i, char := range []rune(s) {
+		assert.Equal(t, off, r.UTF16Offset(i), "UTF16Offset(%d)", i)
+		assert.Equal(t, i, r.PointFromUTF16(off), "PointFromUTF16(%d)", off)
+		if utf16Len(char) == 2 {
+			// middle of a surrogate pair
+			assert.Equal(t, i, r.PointFromUTF16(off+1))
+		}
+		off += utf16Len(char)
+	}
+	assert.Equal(t, off, r.UTF16Offset(r.Runes()))
+	assert.Equal(t, r.Runes(), r.PointFromUTF16(off))
+	assert.Equal(t, -1, r.UTF16Offset(r.Runes()+1))
+	assert.Equal(t, -1, r.PointFromUTF16(off+1))
+}
+
+func TestRope_InsertEraseUTF16(t *testing.T) {
+	const alphabet = "a😀b\n你𝄞"
+	ar := []rune(alphabet)
+	rnd := rand.New(rand.NewSource(1337))
+
+	r := New()
+	var u []uint16
+	for i := 0; i < 300; i++ {
+		// pick offsets that do not split surrogate pairs
+		rs := utf16.Decode(u)
+		at := rnd.Intn(len(rs) + 1)
+		off := len(utf16.Encode(rs[:at]))
+		if rnd.Intn(3) == 0 {
+			n := len(utf16.Encode(rs[at:min(len(rs), at+rnd.Intn(50))]))
+			if err := r.EraseAtUTF16(off, n); err != nil {
+				t.Fatal(err)
+			}
+			u = append(u[:off:off], u[off+n:]...)
+		} else {
+			ins := make([]rune, rnd.Intn(50))
+			for j := range ins {
+				ins[j] = ar[rnd.Intn(len(ar))]
+			}
+			if err := r.InsertUTF16(off, string(ins)); err != nil {
+				t.Fatal(err)
+			}
+			u = append(u[:off:off], append(utf16.Encode(ins), u[off:]...)...)
+		}
+		if !assert.Equal(t, string(utf16.Decode(u)), r.String()) {
+			t.FailNow()
+		}
+		assert.Equal(t, len(u), r.UTF16Len())
+	}
+	validRope(t, r)
+}
@@ -1,22 +1,57 @@
 package skiprope
 
 import (
+	"bytes"
 	"math/rand"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 	// "log"
 )
 
-var src = rand.New(rand.NewSource(time.Now().UnixNano()))
+var (
+	src     = rand.New(rand.NewSource(time.Now().UnixNano()))
+	srcLock sync.Mutex // src is shared by all the ropes which do not have a source of their own
+)
+
+// randInt returns the height of a new knot. The rope's own source of randomness is used if it has one.
+func (r *Rope) randInt() (retVal int) {
+	rnd := r.rand
+	if rnd == nil {
+		srcLock.Lock()
+		defer srcLock.Unlock()
+		rnd = src
+	}
 
-func randInt() (retVal int) {
+	bias := r.heightBias()
 	retVal = 1
-	for retVal < MaxHeight-1 && src.Intn(100) < Bias {
+	for retVal < MaxHeight-1 && rnd.Intn(100) < bias {
 		retVal++
 	}
 	return retVal
 }
 
+// Seed seeds the source of randomness shared by all the ropes which do not have one of their own (see Options),
+// so that the shapes of their skiplists can be reproduced - for benchmarks, say.
+func Seed(seed int64) {
+	srcLock.Lock()
+	src.Seed(seed)
+	srcLock.Unlock()
+}
+
+// newRand returns a new source of randomness for a rope, seeded from the shared source.
+func newRand() *rand.Rand {
+	srcLock.Lock()
+	defer srcLock.Unlock()
+	return rand.New(rand.NewSource(src.Int63()))
+}
+
+// epochs is the last epoch handed out to a rope
+var epochs uint64
+
+func nextEpoch() uint64 { return atomic.AddUint64(&epochs, 1) }
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -35,6 +70,61 @@ func clamp(a, minVal, maxVal int) int {
 	return max(minVal, min(maxVal, a))
 }
 
+// chunk returns the number of bytes and runes at the start of a slice of bytes that fit in a knot of the given size without splitting a rune.
+func chunk(a []byte, size int) (n, runes int) {
+	for n < len(a) {
+		_, width := utf8.DecodeRune(a[n:])
+		if n+width > size {
+			break
+		}
+		n += width
+		runes++
+	}
+	return n, runes
+}
+
+// fullRunes returns the length of the longest prefix of a slice of bytes which does not end with an incomplete UTF-8 sequence.
+func fullRunes(a []byte) int {
+	for i := len(a) - 1; i >= 0 && i >= len(a)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(a[i]) {
+			if utf8.FullRune(a[i:]) {
+				return len(a)
+			}
+			return i
+		}
+	}
+	return len(a)
+}
+
+// countLines counts the number of newlines in a slice of bytes
+func countLines(a []byte) int { return bytes.Count(a, newline) }
+
+var newline = []byte{'\n'}
+
+// countUTF16 counts the number of UTF-16 code units required to encode a slice of bytes
+func countUTF16(a []byte) (retVal int) {
+	for i := 0; i < len(a); {
+		if a[i] < utf8.RuneSelf {
+			// fast path
+			i++
+			retVal++
+			continue
+		}
+		char, size := utf8.DecodeRune(a[i:])
+		i += size
+		retVal += utf16Len(char)
+	}
+	return retVal
+}
+
+// utf16Len returns the number of UTF-16 code units required to encode the rune
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2 // surrogate pair
+	}
+	return 1
+}
+
 // byteOffset takes a slice of bytes, and returns the index at which the expected number of runes there is
 func byteOffset(a []byte, runes int) (offset int) {
 	if runes == 0 {
@@ -50,52 +140,10 @@ func byteOffset(a []byte, runes int) (offset int) {
 			runeCount++
 			continue
 		}
-		// _, size := utf8.DecodeRune(a[offset:])
-		size := int(first[b] & 7) // no checking of correct runes or not
+		// invalid sequences have to be counted the same way as utf8.RuneCount counts them, so the size cannot be read off the first byte
+		_, size := utf8.DecodeRune(a[offset:])
 		offset += size
 		runeCount++
 	}
-	// original code - commented out for posterity's sake. The loop below has been optimized
-	// for _, size := utf8.DecodeRune(a[offset:]); offset < len(a) && runeCount < runes; _, size = utf8.DecodeRune(a[offset:]) {
-	// 	offset += size
-	// 	runeCount++
-	// }
 	return offset
 }
-
-// first is information about the first byte in a UTF-8 sequence.
-
-const (
-	xx = 0xF1 // invalid: size 1
-	as = 0xF0 // ASCII: size 1
-	s1 = 0x02 // accept 0, size 2
-	s2 = 0x13 // accept 1, size 3
-	s3 = 0x03 // accept 0, size 3
-	s4 = 0x23 // accept 2, size 3
-	s5 = 0x34 // accept 3, size 4
-	s6 = 0x04 // accept 0, size 4
-	s7 = 0x44 // accept 4, size 4
-)
-
-var first = [256]uint8{
-	//   1   2   3   4   5   6   7   8   9   A   B   C   D   E   F
-	as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, // 0x00-0x0F
-	as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, // 0x10-0x1F
-	as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, // 0x20-0x2F
-	as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, // 0x30-0x3F
-	as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, // 0x40-0x4F
-	as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, // 0x50-0x5F
-	as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, // 0x60-0x6F
-	as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, as, // 0x70-0x7F
-
-	//   1   2   3   4   5   6   7   8   9   A   B   C   D   E   F
-	xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, // 0x80-0x8F
-	xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, // 0x90-0x9F
-	xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, // 0xA0-0xAF
-	xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, // 0xB0-0xBF
-	xx, xx, s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, // 0xC0-0xCF
-	s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, s1, // 0xD0-0xDF
-	s2, s3, s3, s3, s3, s3, s3, s3, s3, s3, s3, s3, s3, s4, s3, s3, // 0xE0-0xEF
-	s5, s6, s6, s6, s7, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, xx, // 0xF0-0xFF
-
-}
@@ -0,0 +1,117 @@
+package skiprope
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrCorrupt is the error that Validate wraps when the rope is corrupt.
+var ErrCorrupt = errors.New("Corrupt rope")
+
+// Validate checks the invariants of the skiplist of the rope, and returns an error wrapping ErrCorrupt describing the first one that does not hold.
+// These are:
+//   - the heights of the knots are between 1 and MaxHeight, and only the head reaches the top level
+//   - no knot uses more bytes than its bucket holds, and no knot but the head is empty
+//   - no UTF-8 sequence is split between two knots
+//   - the bytes, runes, lines and UTF-16 code units skipped by every link at every level agree with the contents of the knots
+//
+// A rope that only ever was modified by its methods is always valid, so Validate is meant for fuzzers and debugging.
+// The exception is a rope into which ill-formed UTF-8 was inserted: the bytes on either side of an edit may then make up a
+// sequence which is split between two knots, and which is counted as several runes.
+// It takes time linear in the size of the rope.
+func (r *Rope) Validate() error {
+	if r.Head.height < 1 || r.Head.height > MaxHeight || r.Head.height > len(r.Head.nexts) {
+		return fmt.Errorf("%w: head has height %d", ErrCorrupt, r.Head.height)
+	}
+	if r.Head.nexts[r.Head.height-1].knot != nil {
+		return fmt.Errorf("%w: top level of the head links to a knot", ErrCorrupt)
+	}
+
+	// s holds, for every level, the last knot seen that has a link at that level, and the counts from the start of the rope to it
+	var s [MaxHeight]skipknot
+	var total skipknot
+	for i := 0; i < r.Head.height; i++ {
+		s[i].knot = &r.Head
+	}
+
+	for n, k := 0, &r.Head; k != nil; n, k = n+1, k.nexts[0].knot {
+		switch {
+		case k.height < 1 || k.height > len(k.nexts):
+			return fmt.Errorf("%w: knot %d has height %d", ErrCorrupt, n, k.height)
+		case k != &r.Head && k.height >= r.Head.height:
+			return fmt.Errorf("%w: knot %d has height %d, which is not less than the height of the head (%d)", ErrCorrupt, n, k.height, r.Head.height)
+		case k.used < 0 || k.used > len(k.data):
+			return fmt.Errorf("%w: knot %d uses %d bytes of a %d byte bucket", ErrCorrupt, n, k.used, len(k.data))
+		case k.used == 0 && k != &r.Head:
+			return fmt.Errorf("%w: knot %d is empty", ErrCorrupt, n)
+		}
+
+		data := k.data[:k.used]
+		if next := k.nexts[0].knot; next != nil && splitsRune(data, next.data[:min(next.used, len(next.data))]) {
+			return fmt.Errorf("%w: a UTF-8 sequence is split between knots %d and %d", ErrCorrupt, n, n+1)
+		}
+
+		link := k.nexts[0]
+		switch {
+		case link.skipped != k.used:
+			return fmt.Errorf("%w: knot %d has %d bytes, but its link skips %d", ErrCorrupt, n, k.used, link.skipped)
+		case link.skippedRunes != utf8.RuneCount(data):
+			return fmt.Errorf("%w: knot %d has %d runes, but its link skips %d", ErrCorrupt, n, utf8.RuneCount(data), link.skippedRunes)
+		case link.skippedLines != countLines(data):
+			return fmt.Errorf("%w: knot %d has %d newlines, but its link skips %d", ErrCorrupt, n, countLines(data), link.skippedLines)
+		case link.skippedUTF16 != countUTF16(data):
+			return fmt.Errorf("%w: knot %d has %d UTF-16 code units, but its link skips %d", ErrCorrupt, n, countUTF16(data), link.skippedUTF16)
+		}
+
+		for i := 0; i < k.height; i++ {
+			if s[i].knot != k {
+				return fmt.Errorf("%w: knot %d cannot be reached at level %d", ErrCorrupt, n, i)
+			}
+			if s[i].skipped != total.skipped || s[i].skippedRunes != total.skippedRunes ||
+				s[i].skippedLines != total.skippedLines || s[i].skippedUTF16 != total.skippedUTF16 {
+				return fmt.Errorf("%w: links at level %d skip to (%d bytes, %d runes, %d lines, %d code units) at knot %d, which starts at (%d, %d, %d, %d)",
+					ErrCorrupt, i, s[i].skipped, s[i].skippedRunes, s[i].skippedLines, s[i].skippedUTF16,
+					n, total.skipped, total.skippedRunes, total.skippedLines, total.skippedUTF16)
+			}
+			s[i].knot = k.nexts[i].knot
+			s[i].skipped += k.nexts[i].skipped
+			s[i].skippedRunes += k.nexts[i].skippedRunes
+			s[i].skippedLines += k.nexts[i].skippedLines
+			s[i].skippedUTF16 += k.nexts[i].skippedUTF16
+		}
+		total.skipped += link.skipped
+		total.skippedRunes += link.skippedRunes
+		total.skippedLines += link.skippedLines
+		total.skippedUTF16 += link.skippedUTF16
+	}
+
+	for i := 0; i < r.Head.height; i++ {
+		if s[i].knot != nil {
+			return fmt.Errorf("%w: level %d links to a knot that is not in the rope", ErrCorrupt, i)
+		}
+		if s[i] != total {
+			return fmt.Errorf("%w: links at level %d skip (%d bytes, %d runes, %d lines, %d code units) in all, but the rope has (%d, %d, %d, %d)",
+				ErrCorrupt, i, s[i].skipped, s[i].skippedRunes, s[i].skippedLines, s[i].skippedUTF16,
+				total.skipped, total.skippedRunes, total.skippedLines, total.skippedUTF16)
+		}
+	}
+
+	if r.size != total.skipped || r.runes != total.skippedRunes || r.lines != total.skippedLines || r.codeUnits != total.skippedUTF16 {
+		return fmt.Errorf("%w: rope counts (%d bytes, %d runes, %d lines, %d code units), but has (%d, %d, %d, %d)",
+			ErrCorrupt, r.size, r.runes, r.lines, r.codeUnits, total.skipped, total.skippedRunes, total.skippedLines, total.skippedUTF16)
+	}
+	return nil
+}
+
+// splitsRune checks if a valid UTF-8 sequence starts at the end of a and continues at the start of b.
+func splitsRune(a, b []byte) bool {
+	tail := a[fullRunes(a):]
+	if len(tail) == 0 || len(b) == 0 {
+		return false
+	}
+	buf := make([]byte, 0, 2*utf8.UTFMax)
+	buf = append(append(buf, tail...), b[:min(len(b), utf8.UTFMax)]...)
+	_, size := utf8.DecodeRune(buf)
+	return size > len(tail)
+}
@@ -0,0 +1,76 @@
+package skiprope
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRope_Validate(t *testing.T) {
+	assert := assert.New(t)
+
+	build := func() *Rope {
+		r := NewWithOptions(Options{Seed: 1, BucketSize: 8})
+		if err := r.Insert(0, strings.Repeat("héllo wörld\n", 20)); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.EraseAt(7, 30); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	assert.NoError(New().Validate())
+	assert.NoError(build().Validate())
+
+	// tallest knot which is not the head
+	tallest := func(r *Rope) *knot {
+		var retVal *knot
+		for k := r.Head.nexts[0].knot; k != nil; k = k.nexts[0].knot {
+			if retVal == nil || k.height > retVal.height {
+				retVal = k
+			}
+		}
+		return retVal
+	}
+
+	corruptions := []struct {
+		name    string
+		corrupt func(r *Rope)
+		msg     string
+	}{
+		{"size", func(r *Rope) { r.size++ }, "rope counts"},
+		{"lines", func(r *Rope) { r.lines-- }, "rope counts"},
+		{"used", func(r *Rope) { r.Head.nexts[0].knot.used = 9 }, "uses 9 bytes of a 8 byte bucket"},
+		{"empty", func(r *Rope) { r.Head.nexts[0].knot.used, r.Head.nexts[0].knot.nexts[0].skipped = 0, 0 }, "is empty"},
+		{"height", func(r *Rope) { tallest(r).height = 0 }, "has height 0"},
+		{"skipped", func(r *Rope) { r.Head.nexts[0].knot.nexts[0].skipped++ }, "bytes, but its link skips"},
+		{"skippedRunes", func(r *Rope) { r.Head.nexts[0].knot.nexts[0].skippedRunes-- }, "runes, but its link skips"},
+		{"skippedUTF16", func(r *Rope) { r.Head.nexts[0].knot.nexts[0].skippedUTF16++ }, "code units, but its link skips"},
+		{"upper level", func(r *Rope) {
+			k := tallest(r)
+			k.nexts[k.height-1].skippedRunes++
+		}, "links at level"},
+		{"split rune", func(r *Rope) {
+			// move the first byte of "é" to the end of the head
+			k := r.Head.nexts[0].knot
+			i := strings.IndexRune(string(k.data[:k.used]), 'é')
+			if i < 0 {
+				t.Fatal("expected an é in the first knot")
+			}
+			copy(k.data[:k.used], k.data[i+1:k.used])
+			r.Head.data[r.Head.used] = 0xc3
+			r.Head.used++
+		}, "split between knots"},
+	}
+	for _, c := range corruptions {
+		r := build()
+		c.corrupt(r)
+		err := r.Validate()
+		if assert.Error(err, c.name) {
+			assert.True(errors.Is(err, ErrCorrupt), c.name)
+			assert.Contains(err.Error(), c.msg, c.name)
+		}
+	}
+}